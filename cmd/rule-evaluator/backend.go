@@ -0,0 +1,96 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/prometheus/prometheus/storage"
+)
+
+// backendKindPromHTTP queries a remote Prometheus-compatible HTTP API, as the
+// rule-evaluator has always done.
+const backendKindPromHTTP = "prometheus-http"
+
+// backendKindStoreAPI queries a Thanos/Cortex gRPC StoreAPI endpoint directly
+// and evaluates rules against it with a local PromQL engine, bypassing the
+// HTTP query path entirely.
+const backendKindStoreAPI = "storeapi-grpc"
+
+// BackendOpts configures which remote-query backend the rule-evaluator uses
+// to evaluate rules and alerting expressions against.
+type BackendOpts struct {
+	Kind string
+
+	// StoreAPIAddress is the gRPC address of a Thanos/Cortex StoreAPI server,
+	// used when Kind is backendKindStoreAPI.
+	StoreAPIAddress string
+}
+
+// SetupFlags registers the flags used to select and configure the query backend.
+func (o *BackendOpts) SetupFlags(a *kingpin.Application) {
+	a.Flag("query.backend", "The backend used to evaluate rules against: prometheus-http queries a Prometheus-compatible HTTP API (the default); storeapi-grpc queries a Thanos/Cortex StoreAPI gRPC endpoint directly with a local PromQL engine.").
+		Default(backendKindPromHTTP).EnumVar(&o.Kind, backendKindPromHTTP, backendKindStoreAPI)
+
+	a.Flag("query.storeapi-address", "gRPC address of a Thanos/Cortex StoreAPI endpoint. Required when --query.backend=storeapi-grpc.").
+		Default("").StringVar(&o.StoreAPIAddress)
+}
+
+// queryBackend abstracts how the rule-evaluator executes instant queries
+// (used for alerting/recording rule expressions) and range selects (used to
+// serve the rule manager's Queryable, e.g. for the `for` duration of alerts).
+type queryBackend interface {
+	// Query evaluates a PromQL expression at time t and returns a vector or
+	// matrix result, mirroring the Prometheus HTTP API's /query endpoint.
+	Query(ctx context.Context, q string, t time.Time) (parser.Value, v1.Warnings, error)
+
+	// Queryable returns the storage.Queryable used by the rule manager to
+	// evaluate alert `for` clauses and recording rule dependencies.
+	Queryable() storage.Queryable
+}
+
+// newQueryBackend builds the queryBackend selected by opts.
+func newQueryBackend(ctx context.Context, opts BackendOpts, v1api v1.API) (queryBackend, error) {
+	switch opts.Kind {
+	case backendKindStoreAPI:
+		if opts.StoreAPIAddress == "" {
+			return nil, fmt.Errorf("--query.storeapi-address is required for --query.backend=%s", backendKindStoreAPI)
+		}
+		return newStoreAPIBackend(ctx, opts.StoreAPIAddress)
+	case backendKindPromHTTP, "":
+		return &promHTTPBackend{v1api: v1api}, nil
+	default:
+		return nil, fmt.Errorf("unknown --query.backend %q", opts.Kind)
+	}
+}
+
+// promHTTPBackend is the original queryBackend implementation, evaluating
+// queries against a remote Prometheus-compatible HTTP API.
+type promHTTPBackend struct {
+	v1api v1.API
+}
+
+func (b *promHTTPBackend) Query(ctx context.Context, q string, t time.Time) (parser.Value, v1.Warnings, error) {
+	return QueryFunc(ctx, q, t, b.v1api)
+}
+
+func (b *promHTTPBackend) Queryable() storage.Queryable {
+	return &queryStorage{api: b.v1api}
+}
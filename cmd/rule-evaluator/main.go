@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
@@ -34,11 +35,12 @@ import (
 	"github.com/GoogleCloudPlatform/prometheus-engine/pkg/export"
 	exportsetup "github.com/GoogleCloudPlatform/prometheus-engine/pkg/export/setup"
 	"github.com/alecthomas/kingpin/v2"
-	"github.com/go-kit/log"
-	"github.com/go-kit/log/level"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/oklog/run"
 	apiv1 "github.com/prometheus/prometheus/web/api/v1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/api/option"
 	apihttp "google.golang.org/api/transport/http"
 	"google.golang.org/grpc"
@@ -55,6 +57,7 @@ import (
 
 	// Import to enable 'kubernetes_sd_configs' to SD config register.
 	_ "github.com/prometheus/prometheus/discovery/kubernetes"
+	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/notifier"
 	"github.com/prometheus/prometheus/promql"
@@ -67,20 +70,26 @@ import (
 const projectIDVar = "PROJECT_ID"
 
 func main() {
-	logger := log.NewJSONLogger(log.NewSyncWriter(os.Stderr))
-	logger = log.With(logger, "ts", log.DefaultTimestampUTC)
-	logger = log.With(logger, "caller", log.DefaultCaller)
-
 	a := kingpin.New("rule", "The Prometheus Rule Evaluator")
 
 	a.HelpFlag.Short('h')
 
+	logLevel := a.Flag("log.level", "Only log messages with the given severity or above.").
+		Default("info").Enum("debug", "info", "warn", "error")
+
+	logFormat := a.Flag("log.format", "Output format of log messages.").
+		Default("json").Enum("json", "logfmt")
+
+	// bootstrapLogger is used for messages logged before flags (and thus the
+	// requested --log.level/--log.format) have been parsed.
+	bootstrapLogger := newLogger("info", "json")
+
 	var defaultProjectID string
 	if metadata.OnGCE() {
 		var err error
 		defaultProjectID, err = metadata.ProjectID()
 		if err != nil {
-			_ = level.Warn(logger).Log("msg", "Unable to detect Google Cloud project", "err", err)
+			bootstrapLogger.Warn("Unable to detect Google Cloud project", "err", err)
 		}
 	}
 
@@ -95,7 +104,7 @@ func main() {
 	// we reuse that constant.
 	version, err := export.Version()
 	if err != nil {
-		_ = level.Error(logger).Log("msg", "Unable to fetch module version", "err", err)
+		bootstrapLogger.Error("Unable to fetch module version", "err", err)
 		os.Exit(1)
 	}
 
@@ -110,6 +119,21 @@ func main() {
 	haOpts := exportsetup.HAOptions{}
 	haOpts.SetupFlags(a)
 
+	tracingOpts := TracingOpts{}
+	tracingOpts.SetupFlags(a)
+
+	backendOpts := BackendOpts{}
+	backendOpts.SetupFlags(a)
+
+	shardOpts := ShardOpts{}
+	shardOpts.SetupFlags(a)
+
+	alertingOpts := AlertingOpts{}
+	alertingOpts.SetupFlags(a)
+
+	retryOpts := RetryOpts{}
+	retryOpts.SetupFlags(a)
+
 	notifierOptions := notifier.Options{Registerer: reg}
 
 	projectID := a.Flag("query.project-id", "Project ID of the Google Cloud Monitoring scoping project to evaluate rules against.").
@@ -128,6 +152,9 @@ func main() {
 	disableAuth := a.Flag("query.debug.disable-auth", "Disable authentication (for debugging purposes).").
 		Default("false").Bool()
 
+	queryLatencyBucketsStr := a.Flag("query.latency-buckets", "Comma-separated list of bucket boundaries, in seconds, for GCM query request latency histograms.").
+		Default(".01,.05,.1,.25,.5,1,2.5,5,10,25,60,120").String()
+
 	listenAddress := a.Flag("web.listen-address", "The address to listen on for HTTP requests.").
 		Default(":9091").String()
 
@@ -139,51 +166,77 @@ func main() {
 
 	extraArgs, err := exportsetup.ExtraArgs()
 	if err != nil {
-		_ = level.Error(logger).Log("msg", "Error parsing commandline arguments", "err", err)
+		bootstrapLogger.Error("Error parsing commandline arguments", "err", err)
 		a.Usage(os.Args[1:])
 		os.Exit(2)
 	}
 	if _, err := a.Parse(append(os.Args[1:], extraArgs...)); err != nil {
-		_ = level.Error(logger).Log("msg", "Error parsing commandline arguments", "err", err)
+		bootstrapLogger.Error("Error parsing commandline arguments", "err", err)
 		a.Usage(os.Args[1:])
 		os.Exit(2)
 	}
 	startTime := time.Now()
 
+	logger := newLogger(*logLevel, *logFormat)
+	goKitLogger := newGoKitLogger(logger)
+
 	if *projectID == "" {
-		_ = level.Error(logger).Log("msg", "no --query.project-id was specified or could be derived from the environment")
+		logger.Error("no --query.project-id was specified or could be derived from the environment")
+		os.Exit(2)
+	}
+
+	if err := shardOpts.validate(); err != nil {
+		logger.Error("Invalid shard flags", "err", err)
 		os.Exit(2)
 	}
 
 	*targetURL = strings.ReplaceAll(*targetURL, projectIDVar, *projectID)
 
+	queryLatencyBuckets, err := parseLatencyBuckets(*queryLatencyBucketsStr)
+	if err != nil {
+		logger.Error("Invalid --query.latency-buckets", "err", err)
+		os.Exit(2)
+	}
+
 	generatorURL := &url.URL{}
 	if *generatorURLStr != "" {
 		var err error
 		generatorURL, err = url.Parse(*generatorURLStr)
 		if err != nil {
-			_ = level.Error(logger).Log("msg", "Invalid --query.generator-url", "err", err)
+			logger.Error("Invalid --query.generator-url", "err", err)
 			os.Exit(2)
 		}
 	}
 
 	// Don't expand external labels on config file loading. It's a feature we like but we want to remain
 	// compatible with Prometheus and this is still an experimental feature, which we don't support.
-	if _, err := config.LoadFile(*configFile, false, false, logger); err != nil {
-		_ = level.Error(logger).Log("msg", fmt.Sprintf("Error loading config (--config.file=%s)", *configFile), "err", err)
+	if _, err := config.LoadFile(*configFile, false, false, goKitLogger); err != nil {
+		logger.Error(fmt.Sprintf("Error loading config (--config.file=%s)", *configFile), "err", err)
 		os.Exit(2)
 	}
 
 	ctx := context.Background()
-	metadataOpts.ExtractMetadata(logger, &exporterOpts)
-	lease, err := haOpts.NewLease(logger, reg)
+
+	shutdownTracing, err := setupTracing(ctx, logger, tracingOpts, version)
 	if err != nil {
-		_ = level.Error(logger).Log("msg", "Unable to setup Cloud Monitoring Exporter lease", "err", err)
+		logger.Error("Setting up OpenTelemetry tracing failed", "err", err)
 		os.Exit(1)
 	}
-	exporter, err := export.New(ctx, logger, reg, exporterOpts, lease)
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.Error("Shutting down OpenTelemetry tracing failed", "err", err)
+		}
+	}()
+
+	metadataOpts.ExtractMetadata(goKitLogger, &exporterOpts)
+	lease, err := haOpts.NewLease(goKitLogger, reg)
 	if err != nil {
-		_ = level.Error(logger).Log("msg", "Creating a Cloud Monitoring Exporter failed", "err", err)
+		logger.Error("Unable to setup Cloud Monitoring Exporter lease", "err", err)
+		os.Exit(1)
+	}
+	exporter, err := export.New(ctx, goKitLogger, reg, exporterOpts, lease)
+	if err != nil {
+		logger.Error("Creating a Cloud Monitoring Exporter failed", "err", err)
 		os.Exit(1)
 	}
 	destination := export.NewStorage(exporter)
@@ -206,41 +259,54 @@ func main() {
 	}
 	transport, err := apihttp.NewTransport(ctxRuleManager, http.DefaultTransport, opts...)
 	if err != nil {
-		_ = level.Error(logger).Log("msg", "Creating proxy HTTP transport failed", "err", err)
+		logger.Error("Creating proxy HTTP transport failed", "err", err)
 		os.Exit(1)
 	}
-	roundTripper := makeInstrumentedRoundTripper(transport, reg)
+	roundTripper := makeInstrumentedRoundTripper(transport, reg, queryLatencyBuckets, retryOpts)
 	client, err := api.NewClient(api.Config{
 		Address:      *targetURL,
 		RoundTripper: roundTripper,
 	})
 	if err != nil {
-		_ = level.Error(logger).Log("msg", "Error creating client", "err", err)
+		logger.Error("Error creating client", "err", err)
 		os.Exit(1)
 	}
 	v1api := v1.NewAPI(client)
 
+	backend, err := newQueryBackend(ctx, backendOpts, v1api)
+	if err != nil {
+		logger.Error("Setting up query backend failed", "err", err)
+		os.Exit(1)
+	}
+
 	queryFunc := func(ctx context.Context, q string, t time.Time) (promql.Vector, error) {
-		v, warnings, err := QueryFunc(ctx, q, t, v1api)
+		ctx, span := tracer.Start(ctx, "rules.QueryFunc", trace.WithAttributes(attribute.String("promql", q)))
+		defer span.End()
+
+		v, warnings, err := backend.Query(ctx, q, t)
 		if len(warnings) > 0 {
-			_ = level.Warn(logger).Log("msg", "Querying Prometheus instance returned warnings", "warn", warnings)
+			logger.Warn("Querying backend returned warnings", "warn", warnings)
+			span.AddEvent("warnings", trace.WithAttributes(attribute.StringSlice("warnings", []string(warnings))))
 		}
 		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
 			return nil, fmt.Errorf("execute query: %w", err)
 		}
 		vec, ok := v.(promql.Vector)
 		if !ok {
-			return nil, fmt.Errorf("Error querying Prometheus, Expected type vector response. Actual type %v", v.Type())
+			err := fmt.Errorf("Error querying backend, Expected type vector response. Actual type %v", v.Type())
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
 		}
 		return vec, nil
 	}
 
-	discoveryManager := discovery.NewManager(ctxDiscover, log.With(logger, "component", "discovery manager notify"), discovery.Name("notify"))
-	notificationManager := notifier.NewManager(&notifierOptions, log.With(logger, "component", "notifier"))
+	discoveryManager := discovery.NewManager(ctxDiscover, newGoKitLogger(logger.With("component", "discovery manager notify")), discovery.Name("notify"))
+	notificationManager := notifier.NewManager(&notifierOptions, newGoKitLogger(logger.With("component", "notifier")))
 
-	externalStorage := &queryStorage{
-		api: v1api,
-	}
+	externalStorage := backend.Queryable()
 
 	ruleManager := rules.NewManager(&rules.ManagerOptions{
 		ExternalURL: generatorURL,
@@ -248,15 +314,39 @@ func main() {
 		Context:     ctxRuleManager,
 		Appendable:  destination,
 		Queryable:   externalStorage,
-		Logger:      logger,
+		Logger:      newGoKitLogger(logger.With("component", "rule manager")),
 		NotifyFunc:  sendAlerts(notificationManager, generatorURL.String()),
 		Metrics:     rules.NewGroupMetrics(reg),
 	})
 
+	var (
+		shardRingInst *shardRing
+		shardDir      string
+	)
+	shardStat := &shardStatus{}
+	if shardOpts.Enabled {
+		// The static peer list is ignored when membership is discovered via
+		// DNS; the ring then starts empty until the first resolution.
+		peers := shardOpts.Peers
+		if shardOpts.PeerDNSName != "" {
+			peers = nil
+		}
+		shardRingInst = newShardRing(shardOpts.ReplicaName, peers)
+
+		shardDir, err = os.MkdirTemp("", "rule-evaluator-shard-")
+		if err != nil {
+			logger.Error("Creating directory for sharded rule files failed", "err", err)
+			os.Exit(1)
+		}
+	}
+
 	reloaders := []reloader{
 		{
-			name:     "notify",
-			reloader: notificationManager.ApplyConfig,
+			name: "notify",
+			reloader: func(cfg *config.Config) error {
+				applyAlertingOpts(cfg, alertingOpts)
+				return notificationManager.ApplyConfig(cfg)
+			},
 		}, {
 			name:     "exporter",
 			reloader: destination.ApplyConfig,
@@ -281,6 +371,16 @@ func main() {
 					}
 					files = append(files, fs...)
 				}
+
+				if shardRingInst != nil {
+					shardedFiles, owned, err := shardRuleFiles(shardRingInst, files, shardDir)
+					if err != nil {
+						return fmt.Errorf("sharding rule files: %w", err)
+					}
+					shardStat.set(owned)
+					files = shardedFiles
+				}
+
 				return ruleManager.Update(
 					time.Duration(cfg.GlobalConfig.EvaluationInterval),
 					files,
@@ -295,8 +395,8 @@ func main() {
 	configMetrics := newConfigMetrics(reg)
 
 	// Do an initial load of the configuration for all components.
-	if err := reloadConfig(*configFile, logger, configMetrics, reloaders...); err != nil {
-		_ = level.Error(logger).Log("msg", "error loading config file.", "err", err)
+	if err := reloadConfig(ctx, *configFile, logger, configMetrics, reloaders...); err != nil {
+		logger.Error("error loading config file.", "err", err)
 		os.Exit(1)
 	}
 
@@ -310,7 +410,7 @@ func main() {
 			func() error {
 				select {
 				case <-term:
-					_ = level.Info(logger).Log("msg", "received SIGTERM, exiting gracefully...")
+					logger.Info("received SIGTERM, exiting gracefully...")
 				case <-cancel:
 				}
 				return nil
@@ -333,7 +433,7 @@ func main() {
 		// Notifier.
 		g.Add(func() error {
 			notificationManager.Run(discoveryManager.SyncCh())
-			_ = level.Info(logger).Log("msg", "Notification manager stopped")
+			logger.Info("Notification manager stopped")
 			return nil
 		},
 			func(error) {
@@ -346,11 +446,11 @@ func main() {
 		g.Add(
 			func() error {
 				err := discoveryManager.Run()
-				_ = level.Info(logger).Log("msg", "Discovery manager stopped")
+				logger.Info("Discovery manager stopped")
 				return err
 			},
 			func(error) {
-				_ = level.Info(logger).Log("msg", "Stopping Discovery manager...")
+				logger.Info("Stopping Discovery manager...")
 				cancelDiscover()
 			},
 		)
@@ -360,10 +460,10 @@ func main() {
 		ctxStorage, cancelStorage := context.WithCancel(ctx)
 		g.Add(func() error {
 			err = destination.Run(ctxStorage)
-			_ = level.Info(logger).Log("msg", "Background processing of storage stopped")
+			logger.Info("Background processing of storage stopped")
 			return err
 		}, func(error) {
-			_ = level.Info(logger).Log("msg", "Stopping background storage processing...")
+			logger.Info("Stopping background storage processing...")
 			cancelStorage()
 		})
 	}
@@ -419,16 +519,37 @@ func main() {
 			}
 
 			if _, err := w.Write(data); err != nil {
-				_ = level.Error(logger).Log("msg", "Unable to write runtime info status", "err", err)
+				logger.Error("Unable to write runtime info status", "err", err)
+			}
+		})
+		// /api/v1/status/shards reports the rule groups currently owned by
+		// this replica under distributed rule-group sharding, for debugging
+		// shard assignment across a multi-replica deployment.
+		http.HandleFunc("/api/v1/status/shards", func(w http.ResponseWriter, _ *http.Request) {
+			response := response{
+				Status: "success",
+				Data: map[string]interface{}{
+					"enabled":     shardOpts.Enabled,
+					"replicaName": shardOpts.ReplicaName,
+					"ownedGroups": shardStat.get(),
+				},
+			}
+			data, err := json.Marshal(response)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Failed to marshal status: %s", err), http.StatusInternalServerError)
+				return
+			}
+			if _, err := w.Write(data); err != nil {
+				logger.Error("Unable to write shard status", "err", err)
 			}
 		})
 		g.Add(func() error {
-			_ = level.Info(logger).Log("msg", "Starting web server", "listen", *listenAddress)
+			logger.Info("Starting web server", "listen", *listenAddress)
 			return server.ListenAndServe()
 		}, func(error) {
 			ctxServer, cancelServer := context.WithTimeout(ctx, time.Minute)
 			if err := server.Shutdown(ctxServer); err != nil {
-				_ = level.Error(logger).Log("msg", "Server failed to shut down gracefully.")
+				logger.Error("Server failed to shut down gracefully.")
 			}
 			cancelServer()
 		})
@@ -443,12 +564,12 @@ func main() {
 				for {
 					select {
 					case <-hup:
-						if err := reloadConfig(*configFile, logger, configMetrics, reloaders...); err != nil {
-							_ = level.Error(logger).Log("msg", "Error reloading config", "err", err)
+						if err := reloadConfig(ctx, *configFile, logger, configMetrics, reloaders...); err != nil {
+							logger.Error("Error reloading config", "err", err)
 						}
 					case rc := <-reloadCh:
-						if err := reloadConfig(*configFile, logger, configMetrics, reloaders...); err != nil {
-							_ = level.Error(logger).Log("msg", "Error reloading config", "err", err)
+						if err := reloadConfig(ctx, *configFile, logger, configMetrics, reloaders...); err != nil {
+							logger.Error("Error reloading config", "err", err)
 							rc <- err
 						} else {
 							rc <- nil
@@ -465,15 +586,36 @@ func main() {
 			},
 		)
 	}
+	if shardRingInst != nil {
+		// Shard membership watcher. Triggers a config reload whenever the
+		// set of peer replicas changes, so that rule groups are reassigned
+		// without waiting for the next scheduled reload.
+		ctxMembership, cancelMembership := context.WithCancel(ctx)
+		g.Add(
+			func() error {
+				watchMembership(ctxMembership, logger, shardOpts, shardRingInst, func() {
+					rc := make(chan error)
+					reloadCh <- rc
+					if err := <-rc; err != nil {
+						logger.Error("Error reloading config after shard membership change", "err", err)
+					}
+				})
+				return nil
+			},
+			func(error) {
+				cancelMembership()
+			},
+		)
+	}
 
 	// Run a test query to check status of rule evaluator.
 	_, err = queryFunc(ctx, "vector(1)", time.Now())
 	if err != nil {
-		_ = level.Error(logger).Log("msg", "Error querying Prometheus instance", "err", err)
+		logger.Error("Error querying Prometheus instance", "err", err)
 	}
 
 	if err := g.Run(); err != nil {
-		_ = level.Error(logger).Log("msg", "Running rule evaluator failed", "err", err)
+		logger.Error("Running rule evaluator failed", "err", err)
 		os.Exit(1)
 	}
 }
@@ -496,7 +638,13 @@ func QueryFunc(ctx context.Context, q string, t time.Time, v1api v1.API) (parser
 
 // sendAlerts returns the rules.NotifyFunc for a Notifier.
 func sendAlerts(s *notifier.Manager, externalURL string) rules.NotifyFunc {
-	return func(_ context.Context, expr string, alerts ...*rules.Alert) {
+	return func(ctx context.Context, expr string, alerts ...*rules.Alert) {
+		_, span := tracer.Start(ctx, "rules.sendAlerts", trace.WithAttributes(
+			attribute.String("promql", expr),
+			attribute.Int("alerts", len(alerts)),
+		))
+		defer span.End()
+
 		var res []*notifier.Alert
 		for _, alert := range alerts {
 			a := &notifier.Alert{
@@ -563,34 +711,45 @@ func (m *configMetrics) setFailure() {
 }
 
 // reloadConfig applies the configuration files.
-func reloadConfig(filename string, logger log.Logger, metrics *configMetrics, rls ...reloader) (err error) {
+func reloadConfig(ctx context.Context, filename string, logger *slog.Logger, metrics *configMetrics, rls ...reloader) (err error) {
+	ctx, span := tracer.Start(ctx, "reloadConfig", trace.WithAttributes(attribute.String("filename", filename)))
+	defer span.End()
+
 	start := time.Now()
 	timings := []interface{}{}
-	_ = level.Info(logger).Log("msg", "Loading configuration file", "filename", filename)
+	logger.Info("Loading configuration file", "filename", filename)
 
-	conf, err := config.LoadFile(filename, false, false, logger)
+	conf, err := config.LoadFile(filename, false, false, newGoKitLogger(logger))
 	if err != nil {
 		metrics.setFailure()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return fmt.Errorf("couldn't load configuration (--config.file=%q): %w", filename, err)
 	}
 
 	failed := false
 	for _, rl := range rls {
+		_, rspan := tracer.Start(ctx, "reloadConfig."+rl.name)
 		rstart := time.Now()
 		if err := rl.reloader(conf); err != nil {
-			_ = level.Error(logger).Log("msg", "Failed to apply configuration", "err", err)
+			logger.Error("Failed to apply configuration", "err", err)
+			rspan.RecordError(err)
+			rspan.SetStatus(codes.Error, err.Error())
 			failed = true
 		}
+		rspan.End()
 		timings = append(timings, rl.name, time.Since(rstart))
 	}
 	if failed {
 		metrics.setFailure()
-		return fmt.Errorf("one or more errors occurred while applying the new configuration (--config.file=%q)", filename)
+		err := fmt.Errorf("one or more errors occurred while applying the new configuration (--config.file=%q)", filename)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
 	}
 
 	metrics.setSuccess()
-	l := []interface{}{"msg", "Completed loading of configuration file", "filename", filename, "totalDuration", time.Since(start)}
-	_ = level.Info(logger).Log(append(l, timings...)...)
+	logger.Info("Completed loading of configuration file", append([]interface{}{"filename", filename, "totalDuration", time.Since(start)}, timings...)...)
 	return nil
 }
 
@@ -620,9 +779,21 @@ func convertModelToPromQLValue(val model.Value) (parser.Value, error) {
 					F: float64(samplePair.Value),
 				}
 			}
+			hpts := make([]promql.HPoint, len(result.Histograms))
+			for j, h := range result.Histograms {
+				fh, err := convertModelHistogramToFloatHistogram(h.Histogram)
+				if err != nil {
+					return nil, err
+				}
+				hpts[j] = promql.HPoint{
+					T: int64(h.Timestamp),
+					H: fh,
+				}
+			}
 			m[i] = promql.Series{
-				Metric: convertMetricToLabel(result.Metric),
-				Floats: pts,
+				Metric:     convertMetricToLabel(result.Metric),
+				Floats:     pts,
+				Histograms: hpts,
 			}
 		}
 		return m, nil
@@ -630,9 +801,14 @@ func convertModelToPromQLValue(val model.Value) (parser.Value, error) {
 	case model.Vector:
 		v := make(promql.Vector, len(results))
 		for i, result := range results {
+			fh, err := convertModelHistogramToFloatHistogram(result.Histogram)
+			if err != nil {
+				return nil, err
+			}
 			v[i] = promql.Sample{
 				T:      int64(result.Timestamp),
 				F:      float64(result.Value),
+				H:      fh,
 				Metric: convertMetricToLabel(result.Metric),
 			}
 		}
@@ -643,6 +819,73 @@ func convertModelToPromQLValue(val model.Value) (parser.Value, error) {
 	}
 }
 
+// convertModelHistogramToFloatHistogram converts a model.SampleHistogram, as
+// returned for native-histogram series by the Prometheus HTTP API, to the
+// promql.FloatHistogram representation used internally by the rule manager.
+// It returns nil, nil if h is nil, i.e. the sample was a plain float value.
+//
+// The HTTP API flattens a native histogram's sparse exponential buckets into
+// a list of explicit (Lower, Upper, Count) boundaries with no schema or span
+// information alongside it, so the original exponential bucket layout can't
+// be reconstructed. What the boundaries *do* carry is enough to rebuild the
+// histogram losslessly as a custom-buckets ("NHCB") FloatHistogram instead,
+// using each bucket's own Upper value as an explicit boundary rather than
+// guessing at an exponential schema.
+//
+// This only handles the common non-negative case: a sample with any bucket
+// extending below zero (other than a zero bucket straddling it) returns an
+// error, since the custom-buckets schema has nowhere to put a negative
+// range. It also doesn't address content negotiation with the query
+// backend - whatever makes it ask for histogram-bearing responses in the
+// first place is a separate, unimplemented prerequisite.
+func convertModelHistogramToFloatHistogram(h *model.SampleHistogram) (*histogram.FloatHistogram, error) {
+	if h == nil {
+		return nil, nil
+	}
+	fh := &histogram.FloatHistogram{
+		Schema:           histogram.CustomBucketsSchema,
+		Count:            float64(h.Count),
+		Sum:              float64(h.Sum),
+		CounterResetHint: histogram.UnknownCounterReset,
+	}
+
+	var bounds, counts []float64
+	for _, b := range h.Buckets {
+		if b == nil {
+			continue
+		}
+		switch {
+		case b.Lower <= 0 && b.Upper >= 0:
+			// The zero bucket straddles zero rather than being part of the
+			// one-sided custom bucket layout; fold it into ZeroCount/
+			// ZeroThreshold instead of modeling it as a custom bucket.
+			threshold := float64(b.Upper)
+			if lo := float64(-b.Lower); lo > threshold {
+				threshold = lo
+			}
+			fh.ZeroThreshold = threshold
+			fh.ZeroCount += float64(b.Count)
+		case b.Lower < 0:
+			return nil, fmt.Errorf("native histogram sample has a negative-range bucket (%v, %v]: converting those to the custom-buckets schema isn't supported", b.Lower, b.Upper)
+		default:
+			bounds = append(bounds, float64(b.Upper))
+			counts = append(counts, float64(b.Count))
+		}
+	}
+
+	if len(counts) > 0 {
+		// The custom-buckets schema represents n buckets with n-1 interior
+		// boundaries, implicitly extending the last bucket to +Inf. Append a
+		// zero-count bucket for that implicit tail so each real bucket's own
+		// Upper is preserved as an interior boundary instead of the highest
+		// one being silently widened to +Inf.
+		fh.CustomValues = bounds
+		fh.PositiveSpans = []histogram.Span{{Offset: 0, Length: uint32(len(counts) + 1)}}
+		fh.PositiveBuckets = append(counts, 0)
+	}
+	return fh, nil
+}
+
 // Converting v1.Warnings to storage.Warnings.
 func convertV1WarningsToStorageWarnings(w v1.Warnings) storage.Warnings {
 	warnings := make(storage.Warnings, len(w))
@@ -737,9 +980,15 @@ func (db *queryAccess) Select(sort bool, hints *storage.SelectHints, matchers ..
 	}
 
 	queryExpression, filteredMatchers := convertMatchersToPromQL(matchers, duration)
+
+	ctx, span := tracer.Start(db.ctx, "queryAccess.Select", trace.WithAttributes(attribute.String("promql", queryExpression)))
+	defer span.End()
+
 	maxt := time.Unix(db.maxt, 0)
-	v, warnings, err := db.query(db.ctx, queryExpression, maxt, db.api)
+	v, warnings, err := db.query(ctx, queryExpression, maxt, db.api)
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return newListSeriesSet(nil, err, warnings)
 	}
 
@@ -761,26 +1010,3 @@ func (db *queryAccess) Close() error {
 	return nil
 }
 
-// makeInstrumentedRoundTripper instruments the original RoundTripper with middleware to observe the request result.
-// The new RoundTripper counts the number of query requests sent to GCM and measures the latency of each request.
-func makeInstrumentedRoundTripper(transport http.RoundTripper, reg prometheus.Registerer) http.RoundTripper {
-	queryCounter := prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "rule_evaluator_query_requests_total",
-			Help: "A counter for query requests sent to GCM.",
-		},
-		[]string{"code", "method"},
-	)
-	queryHistogram := prometheus.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name:    "rule_evaluator_query_requests_latency_seconds",
-			Help:    "Histogram of response latency of query requests sent to GCM.",
-			Buckets: prometheus.DefBuckets,
-		},
-		[]string{"code", "method"},
-	)
-	reg.MustRegister(queryCounter, queryHistogram)
-
-	return promhttp.InstrumentRoundTripperCounter(queryCounter,
-		promhttp.InstrumentRoundTripperDuration(queryHistogram, transport))
-}
@@ -0,0 +1,214 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+)
+
+// parseLatencyBuckets parses the comma-separated list of bucket boundaries,
+// in seconds, given by --query.latency-buckets.
+func parseLatencyBuckets(s string) ([]float64, error) {
+	parts := strings.Split(s, ",")
+	buckets := make([]float64, 0, len(parts))
+	for _, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing bucket boundary %q: %w", p, err)
+		}
+		buckets = append(buckets, v)
+	}
+	return buckets, nil
+}
+
+// makeInstrumentedRoundTripper instruments the original RoundTripper with middleware to observe the request result.
+// The new RoundTripper counts the number of query requests sent to GCM, measures the end-to-end latency of each
+// request, breaks that latency down by connection phase, and tracks the number of requests currently in flight.
+// buckets is applied to every request-latency histogram registered here, so dashboards can aggregate across them.
+// retryOpts configures the retry-with-backoff middleware applied closest to transport.
+func makeInstrumentedRoundTripper(transport http.RoundTripper, reg prometheus.Registerer, buckets []float64, retryOpts RetryOpts) http.RoundTripper {
+	transport = withRetry(transport, reg, retryOpts)
+
+	queryCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rule_evaluator_query_requests_total",
+			Help: "A counter for query requests sent to GCM.",
+		},
+		[]string{"code", "method", "api_endpoint"},
+	)
+	queryHistogram := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "rule_evaluator_query_requests_latency_seconds",
+			Help:    "Histogram of response latency of query requests sent to GCM.",
+			Buckets: buckets,
+		},
+		[]string{"code", "method", "api_endpoint"},
+	)
+	traceHistogram := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "rule_evaluator_query_request_trace_seconds",
+			Help:    "Histogram of per-phase latency (DNS lookup, TCP connect, TLS handshake, time to first byte, body read) of query requests sent to GCM.",
+			Buckets: buckets,
+		},
+		[]string{"phase"},
+	)
+	inFlightGauge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "rule_evaluator_query_in_flight_requests",
+			Help: "A gauge of in-flight query requests to GCM.",
+		},
+	)
+	reg.MustRegister(queryCounter, queryHistogram, traceHistogram, inFlightGauge)
+
+	// Wrap with otelhttp first so the span covers the full instrumented
+	// request, including the Prometheus counter/histogram observation, and
+	// so the outgoing request carries the trace context header.
+	traced := otelhttp.NewTransport(transport, otelhttp.WithTracerProvider(otel.GetTracerProvider()))
+	traced = withClientTraceMetrics(traced, traceHistogram)
+
+	withEndpointLabel := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		endpoint := classifyAPIEndpoint(req.URL.Path)
+		start := time.Now()
+
+		resp, err := traced.RoundTrip(req)
+
+		code := "error"
+		if err == nil {
+			code = strconv.Itoa(resp.StatusCode)
+		}
+		queryCounter.WithLabelValues(code, req.Method, endpoint).Inc()
+		queryHistogram.WithLabelValues(code, req.Method, endpoint).Observe(time.Since(start).Seconds())
+
+		return resp, err
+	})
+
+	return promhttp.InstrumentRoundTripperInFlight(inFlightGauge, withEndpointLabel)
+}
+
+// apiEndpointOther is the bounded-cardinality label value used for any
+// request path that doesn't match one of the known GCM query API endpoints.
+const apiEndpointOther = "other"
+
+// classifyAPIEndpoint classifies a GCM query request path into a bounded set
+// of endpoint labels, following the Prometheus HTTP query API it proxies.
+// Unrecognized paths are classified as apiEndpointOther to bound cardinality.
+func classifyAPIEndpoint(path string) string {
+	switch {
+	case strings.HasSuffix(path, "/api/v1/query"):
+		return "query"
+	case strings.HasSuffix(path, "/api/v1/query_range"):
+		return "query_range"
+	case strings.HasSuffix(path, "/api/v1/series"):
+		return "series"
+	case strings.HasSuffix(path, "/api/v1/labels"):
+		return "labels"
+	case strings.Contains(path, "/api/v1/label/") && strings.HasSuffix(path, "/values"):
+		return "label_values"
+	default:
+		return apiEndpointOther
+	}
+}
+
+// withClientTraceMetrics wraps next with an httptrace.ClientTrace that
+// records how long each phase of the underlying HTTP connection took into
+// hist, labeled by "phase".
+func withClientTraceMetrics(next http.RoundTripper, hist *prometheus.HistogramVec) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		var (
+			dnsStart, connectStart, tlsStart, reqStart time.Time
+			firstByteAt                                time.Time
+		)
+
+		trace := &httptrace.ClientTrace{
+			DNSStart: func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+			DNSDone: func(httptrace.DNSDoneInfo) {
+				if !dnsStart.IsZero() {
+					hist.WithLabelValues("dns_lookup").Observe(time.Since(dnsStart).Seconds())
+				}
+			},
+			ConnectStart: func(string, string) { connectStart = time.Now() },
+			ConnectDone: func(network, addr string, err error) {
+				if err == nil && !connectStart.IsZero() {
+					hist.WithLabelValues("tcp_connect").Observe(time.Since(connectStart).Seconds())
+				}
+			},
+			TLSHandshakeStart: func() { tlsStart = time.Now() },
+			TLSHandshakeDone: func(tls.ConnectionState, error) {
+				if !tlsStart.IsZero() {
+					hist.WithLabelValues("tls_handshake").Observe(time.Since(tlsStart).Seconds())
+				}
+			},
+			WroteRequest: func(httptrace.WroteRequestInfo) { reqStart = time.Now() },
+			GotFirstResponseByte: func() {
+				firstByteAt = time.Now()
+				if !reqStart.IsZero() {
+					hist.WithLabelValues("time_to_first_byte").Observe(firstByteAt.Sub(reqStart).Seconds())
+				}
+			},
+		}
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+		resp, err := next.RoundTrip(req)
+		if err != nil {
+			return resp, err
+		}
+		resp.Body = &tracedReadCloser{
+			ReadCloser: resp.Body,
+			onClose: func() {
+				if !firstByteAt.IsZero() {
+					hist.WithLabelValues("body_read").Observe(time.Since(firstByteAt).Seconds())
+				}
+			},
+		}
+		return resp, nil
+	})
+}
+
+// tracedReadCloser wraps an http.Response.Body to invoke onClose the first
+// time the body is closed, whether or not it was read to the end, so the
+// body-read phase duration can be observed.
+type tracedReadCloser struct {
+	io.ReadCloser
+	onClose func()
+	closed  bool
+}
+
+func (r *tracedReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	if !r.closed {
+		r.closed = true
+		r.onClose()
+	}
+	return err
+}
+
+// roundTripperFunc adapts a function to the http.RoundTripper interface.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,216 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RetryOpts configures retry-with-backoff behavior for GCM query requests.
+type RetryOpts struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	AttemptTimeout time.Duration
+}
+
+// SetupFlags registers the flags used to configure GCM query request retries.
+func (o *RetryOpts) SetupFlags(a *kingpin.Application) {
+	a.Flag("query.retry.max-retries", "Maximum number of times to retry a GCM query request that fails with a retryable error.").
+		Default("3").IntVar(&o.MaxRetries)
+	a.Flag("query.retry.initial-backoff", "Initial backoff duration before the first retry of a GCM query request.").
+		Default("200ms").DurationVar(&o.InitialBackoff)
+	a.Flag("query.retry.max-backoff", "Maximum backoff duration between retries of a GCM query request.").
+		Default("5s").DurationVar(&o.MaxBackoff)
+	a.Flag("query.retry.attempt-timeout", "Timeout applied to each individual attempt of a GCM query request before it's retried.").
+		Default("30s").DurationVar(&o.AttemptTimeout)
+}
+
+// withRetry wraps next with middleware that retries idempotent GCM query
+// requests - i.e. any request classified to a known api_endpoint - on 429,
+// 5xx, and the network errors isRetryableError recognizes, using exponential
+// backoff with jitter. A 429 response's Retry-After header, if present,
+// overrides the computed backoff. Retries never outlive the request's
+// Context deadline, so they can't outlast the rule evaluation timeout that
+// set it.
+func withRetry(next http.RoundTripper, reg prometheus.Registerer, opts RetryOpts) http.RoundTripper {
+	retryCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rule_evaluator_query_retries_total",
+			Help: "Number of times a GCM query request was retried, labeled by the response code of the failed attempt.",
+		},
+		[]string{"code", "method", "api_endpoint"},
+	)
+	exhaustedCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "rule_evaluator_query_retry_exhausted_total",
+			Help: "Number of GCM query requests that still failed after exhausting all retries.",
+		},
+	)
+	reg.MustRegister(retryCounter, exhaustedCounter)
+
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		endpoint := classifyAPIEndpoint(req.URL.Path)
+		if endpoint == apiEndpointOther {
+			// Unclassified requests aren't known to be idempotent; don't retry them.
+			return next.RoundTrip(req)
+		}
+
+		backoff := opts.InitialBackoff
+
+		for attempt := 0; ; attempt++ {
+			attemptReq := req
+			if attempt > 0 {
+				body, err := replayableBody(req)
+				if err != nil {
+					return nil, err
+				}
+				attemptReq = req.Clone(req.Context())
+				attemptReq.Body = body
+			}
+
+			ctx, cancel := context.WithTimeout(attemptReq.Context(), opts.AttemptTimeout)
+			resp, err := next.RoundTrip(attemptReq.WithContext(ctx))
+
+			retryAfter, retryable := isRetryable(resp, err)
+			if !retryable || attempt >= opts.MaxRetries {
+				if retryable {
+					exhaustedCounter.Inc()
+				}
+				if resp == nil {
+					cancel()
+				} else {
+					// The caller hasn't read the body yet: cancel()'ing now would
+					// tear down the read along with it. Defer the cancel until
+					// the body is closed instead.
+					resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+				}
+				return resp, err
+			}
+
+			code := "error"
+			if resp != nil {
+				code = strconv.Itoa(resp.StatusCode)
+				io.Copy(io.Discard, resp.Body) //nolint:errcheck
+				resp.Body.Close()
+			}
+			retryCounter.WithLabelValues(code, req.Method, endpoint).Inc()
+			cancel()
+
+			wait := backoff
+			if retryAfter > 0 {
+				wait = retryAfter
+			}
+			wait = addJitter(wait)
+
+			select {
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			case <-time.After(wait):
+			}
+
+			backoff *= 2
+			if backoff > opts.MaxBackoff {
+				backoff = opts.MaxBackoff
+			}
+		}
+	})
+}
+
+// isRetryable reports whether the attempt that produced resp/err should be
+// retried, and, if the response was a 429 with a Retry-After header, the
+// duration to wait before retrying.
+func isRetryable(resp *http.Response, err error) (retryAfter time.Duration, retryable bool) {
+	if err != nil {
+		return 0, isRetryableError(err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return parseRetryAfter(resp.Header.Get("Retry-After")), true
+	case resp.StatusCode >= 500:
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// isRetryableError reports whether err is a transient network error worth
+// retrying, such as a connection reset or a timed-out attempt.
+func isRetryableError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr interface{ Timeout() bool }
+	if errors.As(err, &netErr) {
+		return netErr.Timeout()
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header value given in seconds,
+// returning 0 if it's absent or malformed.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	secs, err := strconv.Atoi(v)
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// addJitter returns d plus up to 20% random jitter, to avoid retry storms
+// from many replicas backing off in lockstep.
+func addJitter(d time.Duration) time.Duration {
+	return d + time.Duration(rand.Int63n(int64(d)/5+1))
+}
+
+// cancelOnCloseBody defers canceling the context that bounds a RoundTrip's
+// attempt timeout until the response body is closed, so the cancellation
+// doesn't tear down a read still in progress on the body it owns.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// replayableBody returns a fresh copy of req's body for a retry attempt,
+// using GetBody if the request supports it (e.g. it was built via NewRequest
+// with a buffered body, as api.Client POST/GET requests are).
+func replayableBody(req *http.Request) (io.ReadCloser, error) {
+	if req.Body == nil || req.GetBody == nil {
+		return req.Body, nil
+	}
+	return req.GetBody()
+}
@@ -0,0 +1,260 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"gopkg.in/yaml.v3"
+)
+
+// ShardOpts configures distributed rule-group sharding across rule-evaluator
+// replicas, so that in multi-replica deployments each rule group is
+// evaluated - and each of its alerts sent - by exactly one replica, instead
+// of every replica re-evaluating every group and double-sending alerts.
+type ShardOpts struct {
+	Enabled bool
+
+	// ReplicaName uniquely identifies this replica among its peers, e.g. the
+	// pod name. It must also appear in Peers for this replica to be assigned
+	// any rule groups. When PeerDNSName is set, peers are identified by the
+	// addresses that name resolves to, so ReplicaName must then be this
+	// replica's own resolved address.
+	ReplicaName string
+
+	// Peers is the static set of replica names participating in shard
+	// assignment. Ignored if PeerDNSName is set.
+	Peers []string
+
+	// PeerDNSName, if set, is resolved periodically to discover replica
+	// membership instead of using the static Peers list, e.g. the name of a
+	// headless Kubernetes Service fronting all replicas.
+	PeerDNSName  string
+	PollInterval time.Duration
+}
+
+// SetupFlags registers the flags used to configure distributed rule-group sharding.
+func (o *ShardOpts) SetupFlags(a *kingpin.Application) {
+	a.Flag("shard.enable", "Enable distributed rule-group sharding across replicas so that each rule group in --rules.file is evaluated by exactly one replica.").
+		Default("false").BoolVar(&o.Enabled)
+	a.Flag("shard.replica-name", "Unique name of this replica used for shard assignment, e.g. the pod name. Required when --shard.enable is set. With --shard.peer-dns-name, this must be the replica's own address as returned by that DNS lookup.").
+		Default("").StringVar(&o.ReplicaName)
+	a.Flag("shard.peer", "Name of a peer replica participating in shard assignment, including this replica. Repeatable. Ignored if --shard.peer-dns-name is set.").
+		StringsVar(&o.Peers)
+	a.Flag("shard.peer-dns-name", "DNS name resolved periodically to discover peer replica addresses for shard assignment, e.g. a headless Kubernetes Service name. Takes precedence over --shard.peer.").
+		Default("").StringVar(&o.PeerDNSName)
+	a.Flag("shard.poll-interval", "How often to re-resolve --shard.peer-dns-name to detect replica membership changes.").
+		Default("30s").DurationVar(&o.PollInterval)
+}
+
+// validate fails fast on shard flag combinations that would otherwise leave
+// every rule group silently unowned: --shard.replica-name is required when
+// --shard.enable is set, and, when peer membership is static rather than
+// discovered via --shard.peer-dns-name, it must also appear in --shard.peer
+// or shardRing.Owns would never match this replica against any peer.
+func (o ShardOpts) validate() error {
+	if !o.Enabled {
+		return nil
+	}
+	if o.ReplicaName == "" {
+		return fmt.Errorf("--shard.replica-name is required when --shard.enable is set")
+	}
+	if o.PeerDNSName == "" {
+		for _, p := range o.Peers {
+			if p == o.ReplicaName {
+				return nil
+			}
+		}
+		return fmt.Errorf("--shard.replica-name %q must be included in --shard.peer", o.ReplicaName)
+	}
+	return nil
+}
+
+// shardRing assigns rule groups to replicas using rendezvous (highest random
+// weight) hashing over the current peer set. Unlike a simple mod-based hash,
+// rendezvous hashing means a membership change only reshuffles the groups
+// owned by the peer that joined or left, not every group.
+type shardRing struct {
+	mu    sync.RWMutex
+	self  string
+	peers []string
+}
+
+// newShardRing returns a shardRing that assigns groups among peers, with
+// self identifying which assignments belong to the local replica.
+func newShardRing(self string, peers []string) *shardRing {
+	r := &shardRing{self: self}
+	r.SetPeers(peers)
+	return r
+}
+
+// SetPeers replaces the current peer set and reports whether membership
+// actually changed.
+func (r *shardRing) SetPeers(peers []string) bool {
+	sorted := append([]string(nil), peers...)
+	sort.Strings(sorted)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stringSlicesEqual(r.peers, sorted) {
+		return false
+	}
+	r.peers = sorted
+	return true
+}
+
+// Owns reports whether key is assigned to the local replica under the
+// current peer set. It returns false if self isn't among the peers.
+func (r *shardRing) Owns(key string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var owner string
+	var best uint64
+	for _, p := range r.peers {
+		score := rendezvousScore(key, p)
+		if owner == "" || score > best {
+			owner, best = p, score
+		}
+	}
+	return owner == r.self
+}
+
+func rendezvousScore(key, peer string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(peer))
+	return h.Sum64()
+}
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// shardStatus holds the rule groups currently owned by this replica under
+// distributed rule-group sharding, exposed via /api/v1/status/shards.
+type shardStatus struct {
+	mu    sync.RWMutex
+	owned []string
+}
+
+func (s *shardStatus) set(owned []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.owned = owned
+}
+
+func (s *shardStatus) get() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string(nil), s.owned...)
+}
+
+// shardGroupKey identifies a rule group for shard-assignment purposes.
+func shardGroupKey(file, group string) string {
+	return file + "/" + group
+}
+
+// shardRuleFiles reads the rule files matched by files, keeps only the rule
+// groups owned by ring, and writes the retained groups to new files under
+// dir so they can be handed to ruleManager.Update. It returns the filtered
+// file paths to load and the keys of the groups retained, for reporting via
+// shardStatus.
+func shardRuleFiles(ring *shardRing, files []string, dir string) ([]string, []string, error) {
+	var (
+		outFiles []string
+		owned    []string
+	)
+	for _, f := range files {
+		rgs, errs := rulefmt.ParseFile(f)
+		if len(errs) > 0 {
+			return nil, nil, fmt.Errorf("parsing rule file %s: %w", f, errs[0])
+		}
+
+		kept := make([]rulefmt.RuleGroup, 0, len(rgs.Groups))
+		for _, g := range rgs.Groups {
+			key := shardGroupKey(f, g.Name)
+			if ring.Owns(key) {
+				kept = append(kept, g)
+				owned = append(owned, key)
+			}
+		}
+		if len(kept) == 0 {
+			continue
+		}
+
+		data, err := yaml.Marshal(rulefmt.RuleGroups{Groups: kept})
+		if err != nil {
+			return nil, nil, fmt.Errorf("marshal sharded rule groups for %s: %w", f, err)
+		}
+		out := filepath.Join(dir, fmt.Sprintf("%x.shard.yml", sha256.Sum256([]byte(f))))
+		if err := os.WriteFile(out, data, 0o644); err != nil {
+			return nil, nil, fmt.Errorf("write sharded rule file for %s: %w", f, err)
+		}
+		outFiles = append(outFiles, out)
+	}
+	sort.Strings(owned)
+	return outFiles, owned, nil
+}
+
+// watchMembership polls opts.PeerDNSName for replica membership changes and
+// calls onChange whenever the peer set changes, until ctx is canceled. It is
+// a no-op if opts.PeerDNSName is unset, since the static --shard.peer list
+// can't change without a process restart.
+func watchMembership(ctx context.Context, logger *slog.Logger, opts ShardOpts, ring *shardRing, onChange func()) {
+	if opts.PeerDNSName == "" {
+		return
+	}
+	t := time.NewTicker(opts.PollInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			addrs, err := net.DefaultResolver.LookupHost(ctx, opts.PeerDNSName)
+			if err != nil {
+				logger.Warn("Resolving shard peer DNS name failed", "name", opts.PeerDNSName, "err", err)
+				continue
+			}
+			if ring.SetPeers(addrs) {
+				logger.Info("Shard peer membership changed", "peers", addrs)
+				onChange()
+			}
+		}
+	}
+}
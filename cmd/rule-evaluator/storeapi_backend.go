@@ -0,0 +1,244 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/thanos-io/thanos/pkg/store/storepb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// storeAPIBackend is a queryBackend that talks directly to a Thanos/Cortex
+// StoreAPI gRPC endpoint and evaluates PromQL locally, instead of going
+// through a Prometheus-compatible HTTP query API.
+type storeAPIBackend struct {
+	conn   *grpc.ClientConn
+	client storepb.StoreClient
+	engine *promql.Engine
+}
+
+// newStoreAPIBackend dials the given Thanos/Cortex StoreAPI gRPC address and
+// returns a queryBackend backed by it.
+func newStoreAPIBackend(ctx context.Context, address string) (*storeAPIBackend, error) {
+	conn, err := grpc.DialContext(ctx, address,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("dial StoreAPI endpoint %q: %w", address, err)
+	}
+
+	engine := promql.NewEngine(promql.EngineOpts{
+		MaxSamples:    50e6,
+		Timeout:       2 * time.Minute,
+		LookbackDelta: 5 * time.Minute,
+	})
+
+	return &storeAPIBackend{
+		conn:   conn,
+		client: storepb.NewStoreClient(conn),
+		engine: engine,
+	}, nil
+}
+
+func (b *storeAPIBackend) Query(ctx context.Context, q string, t time.Time) (parser.Value, v1.Warnings, error) {
+	qry, err := b.engine.NewInstantQuery(ctx, b.Queryable(), nil, q, t)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse query: %w", err)
+	}
+	defer qry.Close()
+
+	res := qry.Exec(ctx)
+	if res.Err != nil {
+		return nil, nil, fmt.Errorf("execute query against StoreAPI: %w", res.Err)
+	}
+	var warnings v1.Warnings
+	for _, w := range res.Warnings {
+		warnings = append(warnings, w.Error())
+	}
+	return res.Value, warnings, nil
+}
+
+func (b *storeAPIBackend) Queryable() storage.Queryable {
+	return storage.QueryableFunc(func(mint, maxt int64) (storage.Querier, error) {
+		return &storeAPIQuerier{client: b.client, mint: mint, maxt: maxt}, nil
+	})
+}
+
+// storeAPIQuerier implements storage.Querier on top of a Thanos/Cortex
+// StoreAPI Series() stream.
+type storeAPIQuerier struct {
+	storage.LabelQuerier
+	client     storepb.StoreClient
+	mint, maxt int64
+}
+
+func (q *storeAPIQuerier) Select(sortSeries bool, hints *storage.SelectHints, matchers ...*labels.Matcher) storage.SeriesSet {
+	pbMatchers, err := storepb.PromMatchersToMatchers(matchers...)
+	if err != nil {
+		return storage.ErrSeriesSet(fmt.Errorf("convert matchers for StoreAPI: %w", err))
+	}
+
+	stream, err := q.client.Series(context.Background(), &storepb.SeriesRequest{
+		MinTime:  q.mint,
+		MaxTime:  q.maxt,
+		Matchers: pbMatchers,
+	})
+	if err != nil {
+		return storage.ErrSeriesSet(fmt.Errorf("start StoreAPI series stream: %w", err))
+	}
+
+	var series []storepb.Series
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return storage.ErrSeriesSet(fmt.Errorf("receive from StoreAPI series stream: %w", err))
+		}
+		if s := resp.GetSeries(); s != nil {
+			series = append(series, *s)
+		}
+	}
+	return newStoreAPISeriesSet(series)
+}
+
+func (q *storeAPIQuerier) Close() error { return nil }
+
+type storeAPISeriesSet struct {
+	series []storepb.Series
+	idx    int
+}
+
+func newStoreAPISeriesSet(series []storepb.Series) *storeAPISeriesSet {
+	return &storeAPISeriesSet{series: series, idx: -1}
+}
+
+func (s *storeAPISeriesSet) Next() bool {
+	s.idx++
+	return s.idx < len(s.series)
+}
+
+func (s *storeAPISeriesSet) At() storage.Series {
+	return &storeAPISeries{s.series[s.idx]}
+}
+
+func (s *storeAPISeriesSet) Err() error                 { return nil }
+func (s *storeAPISeriesSet) Warnings() storage.Warnings { return nil }
+
+// storeAPISeries adapts a storepb.Series to the storage.Series iterator
+// interface. Every raw chunk is decoded as an XOR (float) chunk; histogram
+// chunks are not decoded as histograms.
+type storeAPISeries struct {
+	s storepb.Series
+}
+
+func (s *storeAPISeries) Labels() labels.Labels {
+	lset := make(labels.Labels, 0, len(s.s.Labels))
+	for _, l := range s.s.Labels {
+		lset = append(lset, labels.Label{Name: l.Name, Value: l.Value})
+	}
+	return lset
+}
+
+func (s *storeAPISeries) Iterator(it chunkenc.Iterator) chunkenc.Iterator {
+	// StoreAPI returns each series as one or more raw XOR chunks covering
+	// disjoint, increasing time ranges; chain all of them so a query range
+	// spanning more than one chunk (e.g. absent_over_time(...)[7d]) sees every
+	// sample instead of only the first chunk's.
+	its := make([]chunkenc.Iterator, 0, len(s.s.Chunks))
+	for _, chk := range s.s.Chunks {
+		raw := chk.Raw
+		if raw == nil {
+			continue
+		}
+		c, err := chunkenc.FromData(chunkenc.EncXOR, raw.Data)
+		if err != nil {
+			return chunkenc.NewNopIterator()
+		}
+		its = append(its, c.Iterator(nil))
+	}
+	return &chainedChunkIterator{its: its}
+}
+
+// chainedChunkIterator concatenates a sequence of chunkenc.Iterators
+// covering disjoint, increasing time ranges into a single iterator.
+type chainedChunkIterator struct {
+	its []chunkenc.Iterator
+	cur int
+}
+
+func (c *chainedChunkIterator) Next() chunkenc.ValueType {
+	for c.cur < len(c.its) {
+		if typ := c.its[c.cur].Next(); typ != chunkenc.ValNone {
+			return typ
+		}
+		if err := c.its[c.cur].Err(); err != nil {
+			return chunkenc.ValNone
+		}
+		c.cur++
+	}
+	return chunkenc.ValNone
+}
+
+func (c *chainedChunkIterator) Seek(t int64) chunkenc.ValueType {
+	// Skip chunks that end before t entirely, then seek within the first
+	// chunk that might contain it.
+	for c.cur < len(c.its) {
+		if typ := c.its[c.cur].Seek(t); typ != chunkenc.ValNone {
+			return typ
+		}
+		if err := c.its[c.cur].Err(); err != nil {
+			return chunkenc.ValNone
+		}
+		c.cur++
+	}
+	return chunkenc.ValNone
+}
+
+func (c *chainedChunkIterator) At() (int64, float64) {
+	return c.its[c.cur].At()
+}
+
+func (c *chainedChunkIterator) AtHistogram(h *histogram.Histogram) (int64, *histogram.Histogram) {
+	return c.its[c.cur].AtHistogram(h)
+}
+
+func (c *chainedChunkIterator) AtFloatHistogram(fh *histogram.FloatHistogram) (int64, *histogram.FloatHistogram) {
+	return c.its[c.cur].AtFloatHistogram(fh)
+}
+
+func (c *chainedChunkIterator) AtT() int64 {
+	return c.its[c.cur].AtT()
+}
+
+func (c *chainedChunkIterator) Err() error {
+	if c.cur < len(c.its) {
+		return c.its[c.cur].Err()
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/alecthomas/kingpin/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// tracer is used by the rule-evaluator to create spans around rule
+// evaluation, remote queries, config reloads, and alert dispatch.
+var tracer = otel.Tracer("github.com/GoogleCloudPlatform/prometheus-engine/cmd/rule-evaluator")
+
+// TracingOpts holds the OTLP tracing configuration of the rule-evaluator.
+type TracingOpts struct {
+	Endpoint      string
+	Insecure      bool
+	SamplingRatio float64
+	ResourceAttrs map[string]string
+}
+
+// SetupFlags registers the flags used to configure OpenTelemetry tracing.
+func (o *TracingOpts) SetupFlags(a *kingpin.Application) {
+	a.Flag("tracing.otlp.endpoint", "OTLP gRPC endpoint to export traces to. Tracing is disabled if left empty.").
+		Default("").StringVar(&o.Endpoint)
+	a.Flag("tracing.otlp.insecure", "Disable TLS when connecting to the OTLP endpoint.").
+		Default("false").BoolVar(&o.Insecure)
+	a.Flag("tracing.sampling-ratio", "Fraction of rule evaluations and queries to sample traces for.").
+		Default("0.1").Float64Var(&o.SamplingRatio)
+	a.Flag("tracing.resource-attribute", "Additional resource attribute to attach to exported spans, in key=value form. Repeatable.").
+		StringMapVar(&o.ResourceAttrs)
+}
+
+// setupTracing configures the global OTel tracer provider from the given
+// options and returns a shutdown func that flushes and closes the exporter.
+// If no endpoint was configured, tracing is a no-op.
+func setupTracing(ctx context.Context, logger *slog.Logger, opts TracingOpts, version string) (func(context.Context) error, error) {
+	if opts.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	traceOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(opts.Endpoint)}
+	if opts.Insecure {
+		traceOpts = append(traceOpts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, traceOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("create OTLP trace exporter: %w", err)
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.ServiceNameKey.String("rule-evaluator"),
+		semconv.ServiceVersionKey.String(version),
+	}
+	for k, v := range opts.ResourceAttrs {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+	res, err := resource.New(ctx, resource.WithAttributes(attrs...))
+	if err != nil {
+		return nil, fmt.Errorf("build OTel resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SamplingRatio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	logger.Info("OpenTelemetry tracing enabled", "endpoint", opts.Endpoint, "sampling_ratio", opts.SamplingRatio)
+
+	return tp.Shutdown, nil
+}
@@ -0,0 +1,527 @@
+// Copyright 2026 Google LLC
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otlp is a sibling ingestion path to the Prometheus WAL scrape
+// path in package export: it translates incoming OTLP metrics into the
+// same *monitoring_pb.TimeSeries shape seriesCache.populate produces for
+// scraped series, so OTLP and scraped data share identical counter-reset
+// tracking, sample-interval dedup, and eventually the same GCM export
+// machinery.
+package otlp
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/pkg/labels"
+	distribution_pb "google.golang.org/genproto/googleapis/api/distribution"
+	metric_pb "google.golang.org/genproto/googleapis/api/metric"
+	monitoredres_pb "google.golang.org/genproto/googleapis/api/monitoredres"
+	monitoring_pb "google.golang.org/genproto/googleapis/monitoring/v3"
+
+	collectormetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/GoogleCloudPlatform/prometheus-engine/export"
+)
+
+// TemporalityPolicy controls how delta-temporality OTLP sums are
+// handled. GCM only accepts cumulative time series, so a delta point
+// can't be forwarded as-is.
+type TemporalityPolicy string
+
+const (
+	// TemporalityDrop discards delta points. They're counted on the
+	// receiver's per-signal error metric with reason "delta_dropped".
+	TemporalityDrop TemporalityPolicy = "drop"
+	// TemporalityConvertToCumulative accumulates delta points into a
+	// running cumulative per series before handing them to the cache,
+	// the same way a Prometheus counter is always cumulative on the
+	// wire regardless of how the client tracks it internally.
+	TemporalityConvertToCumulative TemporalityPolicy = "convert-to-cumulative"
+)
+
+// Resource attribute keys consulted when mapping an OTLP Resource onto a
+// monitored resource, following the semantic conventions OTel SDKs
+// populate by default.
+const (
+	attrServiceName      = "service.name"
+	attrServiceNamespace = "service.namespace"
+	attrServiceInstance  = "service.instance.id"
+)
+
+// Monitored resource label keys. These mirror package export's unexported
+// keyJob/keyInstance/keyLocation/keyCluster/keyNamespace constants, which
+// aren't visible outside that package.
+const (
+	labelJob       = "job"
+	labelInstance  = "instance"
+	labelLocation  = "location"
+	labelCluster   = "cluster"
+	labelNamespace = "namespace"
+)
+
+// PointSink receives a fully translated, reset-adjusted GCM point ready
+// for export.
+//
+// TODO: this snapshot has no batching/queueing/retry machinery for the
+// WAL scrape path to plug into either -- only seriesCache itself exists,
+// not the exporter that calls it. Until that exists, a PointSink
+// implementation here can only talk to the GCM API directly; there's no
+// shared queue to hand points off to yet.
+type PointSink interface {
+	Send(ctx context.Context, ts *monitoring_pb.TimeSeries, point *monitoring_pb.Point) error
+}
+
+// Receiver translates OTLP ExportMetricsServiceRequest payloads into GCM
+// time series and hands them to a PointSink. Its Export method matches
+// the signature collectormetricspb.MetricsServiceServer requires, so a
+// generated gRPC server type embedding it can register a Receiver
+// directly.
+type Receiver struct {
+	logger      log.Logger
+	cache       *export.OTLPCache
+	temporality TemporalityPolicy
+	sink        PointSink
+
+	errors *prometheus.CounterVec
+
+	mtx          sync.Mutex
+	deltaAccum   map[uint64]float64
+	deltaStartMs map[uint64]int64
+}
+
+// NewReceiver creates a Receiver. temporality governs how delta-aggregated
+// sums are handled, since GCM only accepts cumulatives.
+func NewReceiver(logger log.Logger, cache *export.OTLPCache, temporality TemporalityPolicy, sink PointSink, reg prometheus.Registerer) *Receiver {
+	if logger == nil {
+		logger = log.NewNopLogger()
+	}
+	errs := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gcm_export_otlp_points_rejected_total",
+		Help: "Number of OTLP data points rejected during translation to GCM time series, by signal type and reason.",
+	}, []string{"signal", "reason"})
+	if reg != nil {
+		reg.MustRegister(errs)
+	}
+	return &Receiver{
+		logger:       logger,
+		cache:        cache,
+		temporality:  temporality,
+		sink:         sink,
+		errors:       errs,
+		deltaAccum:   map[uint64]float64{},
+		deltaStartMs: map[uint64]int64{},
+	}
+}
+
+// Export implements collectormetricspb.MetricsServiceServer.
+func (r *Receiver) Export(ctx context.Context, req *collectormetricspb.ExportMetricsServiceRequest) (*collectormetricspb.ExportMetricsServiceResponse, error) {
+	var rejected int64
+	var firstErr error
+
+	for _, rm := range req.ResourceMetrics {
+		resource, resourceLabels := r.extractResource(rm.Resource)
+
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				n, err := r.handleMetric(ctx, resource, resourceLabels, m)
+				rejected += n
+				if err != nil {
+					level.Debug(r.logger).Log("msg", "translate OTLP metric", "metric", m.Name, "err", err)
+					if firstErr == nil {
+						firstErr = err
+					}
+				}
+			}
+		}
+	}
+
+	resp := &collectormetricspb.ExportMetricsServiceResponse{}
+	if rejected > 0 {
+		msg := fmt.Sprintf("%d data points rejected", rejected)
+		if firstErr != nil {
+			msg = firstErr.Error()
+		}
+		resp.PartialSuccess = &collectormetricspb.ExportMetricsPartialSuccess{
+			RejectedDataPoints: rejected,
+			ErrorMessage:       msg,
+		}
+	}
+	return resp, nil
+}
+
+// extractResource maps an OTLP Resource onto the prometheus_target
+// monitored resource when it carries enough identifying attributes, and
+// falls back to generic_task or generic_node otherwise -- the same
+// fallback GCM itself offers for workloads outside of Prometheus's
+// job/instance model.
+func (r *Receiver) extractResource(res *resourcepb.Resource) (*monitoredres_pb.MonitoredResource, labels.Labels) {
+	attrs := attrsToLabels(res.GetAttributes())
+
+	job := attrs.Get(attrServiceName)
+	instance := attrs.Get(attrServiceInstance)
+	if job != "" && instance != "" {
+		return &monitoredres_pb.MonitoredResource{
+			Type: "prometheus_target",
+			Labels: map[string]string{
+				labelLocation:  attrs.Get(labelLocation),
+				labelCluster:   attrs.Get(labelCluster),
+				labelNamespace: attrs.Get(attrServiceNamespace),
+				labelJob:       job,
+				labelInstance:  instance,
+			},
+		}, attrs
+	}
+	if job != "" {
+		// No instance identity, but enough to group the series under a
+		// task rather than attributing it to a whole node.
+		return &monitoredres_pb.MonitoredResource{
+			Type: "generic_task",
+			Labels: map[string]string{
+				labelLocation:  attrs.Get(labelLocation),
+				labelNamespace: attrs.Get(attrServiceNamespace),
+				labelJob:       job,
+				"task_id":      instance,
+			},
+		}, attrs
+	}
+	return &monitoredres_pb.MonitoredResource{
+		Type: "generic_node",
+		Labels: map[string]string{
+			labelLocation:  attrs.Get(labelLocation),
+			labelNamespace: attrs.Get(attrServiceNamespace),
+			"node_id":      instance,
+		},
+	}, attrs
+}
+
+func attrsToLabels(attrs []*commonpb.KeyValue) labels.Labels {
+	b := labels.NewBuilder(labels.EmptyLabels())
+	for _, kv := range attrs {
+		b.Set(kv.Key, attrValueToString(kv.Value))
+	}
+	return b.Labels()
+}
+
+func attrValueToString(v *commonpb.AnyValue) string {
+	if v == nil {
+		return ""
+	}
+	switch val := v.Value.(type) {
+	case *commonpb.AnyValue_StringValue:
+		return val.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return fmt.Sprintf("%t", val.BoolValue)
+	case *commonpb.AnyValue_IntValue:
+		return fmt.Sprintf("%d", val.IntValue)
+	case *commonpb.AnyValue_DoubleValue:
+		return fmt.Sprintf("%g", val.DoubleValue)
+	default:
+		// Arrays, byte strings and key-value lists have no single scalar
+		// representation; stringify rather than drop the attribute.
+		return v.String()
+	}
+}
+
+func (r *Receiver) handleMetric(ctx context.Context, resource *monitoredres_pb.MonitoredResource, resourceLabels labels.Labels, m *metricspb.Metric) (int64, error) {
+	switch data := m.Data.(type) {
+	case *metricspb.Metric_Gauge:
+		return r.handleNumberPoints(ctx, resource, resourceLabels, m.Name, "gauge",
+			metric_pb.MetricDescriptor_GAUGE, false, data.Gauge.DataPoints)
+
+	case *metricspb.Metric_Sum:
+		return r.handleSum(ctx, resource, resourceLabels, m.Name, data.Sum)
+
+	case *metricspb.Metric_Histogram:
+		var rejected int64
+		var err error
+		for _, dp := range data.Histogram.DataPoints {
+			if e := r.handleHistogramPoint(ctx, resource, resourceLabels, m.Name, dp); e != nil {
+				r.errors.WithLabelValues("histogram", "translate").Inc()
+				rejected++
+				err = e
+			}
+		}
+		return rejected, err
+
+	case *metricspb.Metric_ExponentialHistogram:
+		var rejected int64
+		var err error
+		for _, dp := range data.ExponentialHistogram.DataPoints {
+			if e := r.handleExponentialHistogramPoint(ctx, resource, resourceLabels, m.Name, dp); e != nil {
+				r.errors.WithLabelValues("exponential_histogram", "translate").Inc()
+				rejected++
+				err = e
+			}
+		}
+		return rejected, err
+
+	case *metricspb.Metric_Summary:
+		r.errors.WithLabelValues("summary", "unsupported").Inc()
+		return int64(len(data.Summary.DataPoints)), errors.New("OTLP summary metrics are not supported, the same as for scraped Prometheus summaries' quantile handling")
+
+	default:
+		r.errors.WithLabelValues("unknown", "unsupported").Inc()
+		return 0, errors.Errorf("unrecognized OTLP metric data type for %q", m.Name)
+	}
+}
+
+func (r *Receiver) handleSum(ctx context.Context, resource *monitoredres_pb.MonitoredResource, resourceLabels labels.Labels, name string, sum *metricspb.Sum) (int64, error) {
+	var rejected int64
+	var err error
+	for _, dp := range sum.DataPoints {
+		value := numberPointValue(dp)
+		startMs := int64(dp.StartTimeUnixNano / 1e6)
+
+		if sum.AggregationTemporality == metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA {
+			switch r.temporality {
+			case TemporalityConvertToCumulative:
+				key := export.OTLPSeriesKey(name, resourceLabels, attrsToLabels(dp.Attributes))
+				value, startMs = r.accumulateDelta(key, value, startMs)
+			default:
+				r.errors.WithLabelValues("sum", "delta_dropped").Inc()
+				rejected++
+				continue
+			}
+		}
+		if e := r.emit(ctx, resource, resourceLabels, name, dp.Attributes,
+			metric_pb.MetricDescriptor_CUMULATIVE, metric_pb.MetricDescriptor_DOUBLE,
+			int64(dp.TimeUnixNano/1e6), startMs, &monitoring_pb.TypedValue{Value: &monitoring_pb.TypedValue_DoubleValue{DoubleValue: value}}); e != nil {
+			r.errors.WithLabelValues("sum", "translate").Inc()
+			rejected++
+			err = e
+		}
+	}
+	return rejected, err
+}
+
+func (r *Receiver) handleNumberPoints(ctx context.Context, resource *monitoredres_pb.MonitoredResource, resourceLabels labels.Labels, name, signal string, kind metric_pb.MetricDescriptor_MetricKind, monotonic bool, dps []*metricspb.NumberDataPoint) (int64, error) {
+	var rejected int64
+	var err error
+	for _, dp := range dps {
+		v := numberPointValue(dp)
+		if e := r.emit(ctx, resource, resourceLabels, name, dp.Attributes,
+			kind, metric_pb.MetricDescriptor_DOUBLE,
+			int64(dp.TimeUnixNano/1e6), int64(dp.StartTimeUnixNano/1e6),
+			&monitoring_pb.TypedValue{Value: &monitoring_pb.TypedValue_DoubleValue{DoubleValue: v}}); e != nil {
+			r.errors.WithLabelValues(signal, "translate").Inc()
+			rejected++
+			err = e
+		}
+	}
+	return rejected, err
+}
+
+// accumulateDelta folds a delta point into a running cumulative, keyed by
+// the series' cache key, so downstream everything sees an
+// ever-increasing value the way a Prometheus counter would expose it.
+// The first delta observed for a series pins the cumulative's start
+// time; a real accumulator would also need to invalidate this state on
+// an OTLP stream restart, which isn't detectable without a sequence
+// number or reset signal OTLP doesn't provide today.
+func (r *Receiver) accumulateDelta(key uint64, delta float64, startMs int64) (value float64, cumulativeStartMs int64) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, ok := r.deltaStartMs[key]; !ok {
+		r.deltaStartMs[key] = startMs
+	}
+	r.deltaAccum[key] += delta
+	return r.deltaAccum[key], r.deltaStartMs[key]
+}
+
+func numberPointValue(dp *metricspb.NumberDataPoint) float64 {
+	switch v := dp.Value.(type) {
+	case *metricspb.NumberDataPoint_AsDouble:
+		return v.AsDouble
+	case *metricspb.NumberDataPoint_AsInt:
+		return float64(v.AsInt)
+	default:
+		return 0
+	}
+}
+
+func (r *Receiver) handleHistogramPoint(ctx context.Context, resource *monitoredres_pb.MonitoredResource, resourceLabels labels.Labels, name string, dp *metricspb.HistogramDataPoint) error {
+	var mean float64
+	if dp.Count > 0 && dp.Sum != nil {
+		mean = *dp.Sum / float64(dp.Count)
+	}
+	bucketCounts := make([]int64, len(dp.BucketCounts))
+	for i, c := range dp.BucketCounts {
+		bucketCounts[i] = int64(c)
+	}
+	dist := &distribution_pb.Distribution{
+		Count:        int64(dp.Count),
+		Mean:         mean,
+		BucketCounts: bucketCounts,
+		BucketOptions: &distribution_pb.Distribution_BucketOptions{
+			Options: &distribution_pb.Distribution_BucketOptions_ExplicitBuckets{
+				ExplicitBuckets: &distribution_pb.Distribution_BucketOptions_Explicit{
+					Bounds: dp.ExplicitBounds,
+				},
+			},
+		},
+	}
+	return r.emit(ctx, resource, resourceLabels, name, dp.Attributes,
+		metric_pb.MetricDescriptor_CUMULATIVE, metric_pb.MetricDescriptor_DISTRIBUTION,
+		int64(dp.TimeUnixNano/1e6), int64(dp.StartTimeUnixNano/1e6),
+		&monitoring_pb.TypedValue{Value: &monitoring_pb.TypedValue_DistributionValue{DistributionValue: dist}})
+}
+
+// handleExponentialHistogramPoint translates an OTLP exponential
+// histogram into GCM's exponential bucket options.
+//
+// GCM's exponential layout has no negative range, so negative-range
+// buckets are counted in GCM's underflow bucket together with the zero
+// count, which loses their spread.
+func (r *Receiver) handleExponentialHistogramPoint(ctx context.Context, resource *monitoredres_pb.MonitoredResource, resourceLabels labels.Labels, name string, dp *metricspb.ExponentialHistogramDataPoint) error {
+	var mean float64
+	if dp.Count > 0 && dp.Sum != nil {
+		mean = *dp.Sum / float64(dp.Count)
+	}
+
+	growth := math.Pow(2, math.Pow(2, -float64(dp.Scale)))
+	// GCM's exponential bucket option has no offset of its own: its
+	// finite bucket i covers [scale*growth^(i-1), scale*growth^i). Since
+	// an OTLP bucket at index `offset+i-1` covers exactly
+	// (base^(offset+i-1), base^(offset+i)], setting scale to
+	// growth^Offset makes GCM bucket 1 line up with OTLP bucket Offset,
+	// so the raw Positive.BucketCounts can be copied in starting at
+	// index 1 with no padding or shifting.
+	scale := 1.0
+	if dp.Positive != nil {
+		scale = math.Pow(growth, float64(dp.Positive.Offset))
+	}
+
+	var bucketCounts []int64
+	bucketCounts = append(bucketCounts, int64(dp.ZeroCount))
+	if dp.Negative != nil {
+		// Negative-range buckets aren't positioned relative to the
+		// positive ones in GCM's single-sided exponential layout; fold
+		// them into the zero/underflow bucket until that's implemented.
+		for _, c := range dp.Negative.BucketCounts {
+			bucketCounts[0] += int64(c)
+		}
+	}
+	if dp.Positive != nil {
+		for _, c := range dp.Positive.BucketCounts {
+			bucketCounts = append(bucketCounts, int64(c))
+		}
+	}
+
+	dist := &distribution_pb.Distribution{
+		Count: int64(dp.Count),
+		Mean:  mean,
+		BucketOptions: &distribution_pb.Distribution_BucketOptions{
+			Options: &distribution_pb.Distribution_BucketOptions_ExponentialBuckets{
+				ExponentialBuckets: &distribution_pb.Distribution_BucketOptions_Exponential{
+					NumFiniteBuckets: int32(len(bucketCounts) - 1),
+					GrowthFactor:     growth,
+					Scale:            scale,
+				},
+			},
+		},
+		BucketCounts: bucketCounts,
+	}
+	return r.emit(ctx, resource, resourceLabels, name, dp.Attributes,
+		metric_pb.MetricDescriptor_CUMULATIVE, metric_pb.MetricDescriptor_DISTRIBUTION,
+		int64(dp.TimeUnixNano/1e6), int64(dp.StartTimeUnixNano/1e6),
+		&monitoring_pb.TypedValue{Value: &monitoring_pb.TypedValue_DistributionValue{DistributionValue: dist}})
+}
+
+// emit builds (or reuses) the cache entry for the series identified by
+// name/resourceLabels/dpAttrs, runs the sample through the cache's
+// counter-reset tracking and sample-interval dedup exactly as the scrape
+// path does, and forwards the result to the sink.
+func (r *Receiver) emit(ctx context.Context, resource *monitoredres_pb.MonitoredResource, resourceLabels labels.Labels, name string, dpAttrs []*commonpb.KeyValue, kind metric_pb.MetricDescriptor_MetricKind, valueType metric_pb.MetricDescriptor_ValueType, t, ct int64, value *monitoring_pb.TypedValue) error {
+	metricLabels := attrsToLabels(dpAttrs)
+	key := export.OTLPSeriesKey(name, resourceLabels, metricLabels)
+
+	ts := &monitoring_pb.TimeSeries{
+		Resource:   resource,
+		MetricKind: kind,
+		ValueType:  valueType,
+		Metric: &metric_pb.Metric{
+			Type:   name,
+			Labels: metricLabels.Map(),
+		},
+	}
+	r.cache.Put(key, ts)
+
+	// Scalar cumulatives have ct recorded by ResetAdjusted below, which
+	// compares it against the previously recorded one to detect a restart;
+	// recording it here first would hide that change.
+	scalarCumulative := kind == metric_pb.MetricDescriptor_CUMULATIVE && value.GetDistributionValue() == nil
+	if ct != 0 && !scalarCumulative {
+		r.cache.SetCreatedTimestamp(key, ct)
+	}
+
+	adjustedStart := ct
+	adjustedValue := value
+	if kind == metric_pb.MetricDescriptor_CUMULATIVE {
+		if value.GetDistributionValue() != nil {
+			// GCM distributions carry cumulative bucket totals directly
+			// and aren't value-diffed against a running reset value the
+			// way scalar cumulatives are below; the cache is only
+			// consulted for the reset boundary timestamp itself.
+			if adjustedStart == 0 {
+				adjustedStart = t
+			}
+		} else {
+			start, v, ok := r.cache.ResetAdjusted(key, t, typedValueFloat(value), ct)
+			if !ok {
+				return nil
+			}
+			adjustedStart = start
+			adjustedValue = &monitoring_pb.TypedValue{Value: &monitoring_pb.TypedValue_DoubleValue{DoubleValue: v}}
+		}
+	}
+	// key doubles as the series descriptor hash here: unlike the scrape
+	// path, resource+metric type+labels are already baked into it, so
+	// there's no need for a separate hashSeries call.
+	if !r.cache.UpdateSampleInterval(key, adjustedStart, t) {
+		return nil
+	}
+
+	point := &monitoring_pb.Point{
+		Interval: &monitoring_pb.TimeInterval{
+			StartTime: millisToTimestamp(adjustedStart),
+			EndTime:   millisToTimestamp(t),
+		},
+		Value: adjustedValue,
+	}
+	if r.sink == nil {
+		return nil
+	}
+	return r.sink.Send(ctx, ts, point)
+}
+
+func millisToTimestamp(ms int64) *timestamppb.Timestamp {
+	return timestamppb.New(time.UnixMilli(ms))
+}
+
+func typedValueFloat(v *monitoring_pb.TypedValue) float64 {
+	if dv, ok := v.Value.(*monitoring_pb.TypedValue_DoubleValue); ok {
+		return dv.DoubleValue
+	}
+	return 0
+}
@@ -0,0 +1,29 @@
+// Copyright 2026 Google LLC
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+// TODO: wiring a gRPC/HTTP server behind a --otlp.listen-address flag
+// belongs in the collector binary, which doesn't exist in this snapshot
+// (only cmd/rule-evaluator does -- there's no cmd/collector to add the
+// flag to, and no HTTP/gRPC listener setup to follow the conventions
+// of). Once that binary exists, serving this package's Receiver should
+// look like:
+//
+//   lis, err := net.Listen("tcp", *otlpListenAddress)
+//   srv := grpc.NewServer()
+//   collectormetricspb.RegisterMetricsServiceServer(srv, receiver)
+//   go srv.Serve(lis)
+//
+// for OTLP/gRPC, plus an http.Handler decoding OTLP/HTTP protobuf or JSON
+// request bodies into ExportMetricsServiceRequest and calling
+// Receiver.Export directly for OTLP/HTTP, mounted at the conventional
+// /v1/metrics path.
@@ -0,0 +1,107 @@
+// Copyright 2026 Google LLC
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/pkg/labels"
+	monitoring_pb "google.golang.org/genproto/googleapis/monitoring/v3"
+)
+
+// OTLPCache adapts seriesCache for OTLP ingestion. Unlike scraped series,
+// OTLP data points carry no WAL series reference and arrive with no
+// scrape.Target to look up metadata from, so a caller in package otlp
+// builds the TimeSeries descriptor itself and keys it with OTLPSeriesKey
+// rather than going through get/populate. Doing so still gets OTLP series
+// the same counter reset tracking, sample-interval dedup, and refresh
+// jitter as scraped ones.
+type OTLPCache struct {
+	c *seriesCache
+}
+
+// NewOTLPCache creates an OTLPCache. metricsPrefix and opts behave
+// identically to the scrape path's series cache. There are no external
+// labels or WAL checkpoints to garbage collect by for OTLP series, so
+// those seriesCache features are left unused here.
+func NewOTLPCache(logger log.Logger, metricsPrefix string, reg prometheus.Registerer, opts ...seriesCacheOption) *OTLPCache {
+	return &OTLPCache{
+		c: newSeriesCache(logger, metricsPrefix, func() labels.Labels { return nil }, reg, opts...),
+	}
+}
+
+// OTLPSeriesKey computes the stable cache key for an OTLP data point by
+// hashing the resource's labels together with the metric name and the
+// data point's attribute set. Unlike a WAL series reference it's
+// reproducible across restarts, so the same logical series always lands
+// on the same cache entry.
+func OTLPSeriesKey(metricName string, resourceLabels, metricLabels labels.Labels) uint64 {
+	const sep = '\xff'
+	h := hashNew()
+	h = hashAdd(h, metricName)
+	h = hashAddByte(h, sep)
+	for _, l := range resourceLabels {
+		h = hashAddByte(h, sep)
+		h = hashAdd(h, l.Name)
+		h = hashAddByte(h, sep)
+		h = hashAdd(h, l.Value)
+	}
+	h = hashAddByte(h, sep)
+	for _, l := range metricLabels {
+		h = hashAddByte(h, sep)
+		h = hashAdd(h, l.Name)
+		h = hashAddByte(h, sep)
+		h = hashAdd(h, l.Value)
+	}
+	return h
+}
+
+// Put upserts the entry for key with a pre-built TimeSeries descriptor,
+// playing the same role populate does for a scraped series. Callers
+// call Put before the first call to ResetAdjusted for that key; a later
+// Put replaces the descriptor and keeps the entry's reset state.
+func (o *OTLPCache) Put(key uint64, ts *monitoring_pb.TimeSeries) {
+	o.c.mtx.Lock()
+	defer o.c.mtx.Unlock()
+
+	e, ok := o.c.entries[key]
+	if !ok {
+		e = &seriesCacheEntry{}
+		o.c.entries[key] = e
+	}
+	e.proto = ts
+	e.hash = hashSeries(ts)
+	e.setNextRefresh()
+}
+
+// SetCreatedTimestamp records an exact reset boundary for the series
+// referenced by key, observed independently of a sample value -- e.g. a
+// point's start_time_unix_nano seen before its first data point arrives.
+func (o *OTLPCache) SetCreatedTimestamp(key uint64, ct int64) {
+	o.c.setCreatedTimestamp(key, ct)
+}
+
+// ResetAdjusted returns the reset timestamp and adjusted value for a
+// sample of the series referenced by key, or false if the sample should
+// be dropped. ct is the point's start_time_unix_nano converted to
+// milliseconds, or 0 if the point carries no start time. See
+// seriesCache.getResetAdjusted.
+func (o *OTLPCache) ResetAdjusted(key uint64, t int64, v float64, ct int64) (int64, float64, bool) {
+	return o.c.getResetAdjusted(key, t, v, ct, createdTimestampSourceOTLP)
+}
+
+// UpdateSampleInterval attempts to set the new most recent time range for
+// the series with the given descriptor hash, returning false if the
+// sample must be discarded because it overlaps a range already written.
+func (o *OTLPCache) UpdateSampleInterval(hash uint64, start, end int64) bool {
+	return o.c.updateSampleInterval(hash, start, end)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/prometheus/pkg/labels"
 	"github.com/prometheus/prometheus/pkg/textparse"
 	"github.com/prometheus/prometheus/scrape"
@@ -28,13 +29,32 @@ import (
 	monitoring_pb "google.golang.org/genproto/googleapis/monitoring/v3"
 )
 
+// Sources recorded on the gcm_export_created_timestamp_used_total metric,
+// identifying how a counter reset boundary was established: from a created
+// timestamp reported by a scrape or by OTLP, or by the t-1 heuristic.
+const (
+	createdTimestampSourceScrape    = "scrape"
+	createdTimestampSourceOTLP      = "otlp"
+	createdTimestampSourceHeuristic = "heuristic"
+)
+
 type seriesStore interface {
 	// Same interface as the standard map getter.
 	get(ref uint64, target *scrape.Target) (*seriesCacheEntry, bool, error)
 
-	// Get the reset timestamp and adjusted value for the input sample.
-	// If false is returned, the sample should be skipped.
-	getResetAdjusted(ref uint64, t int64, v float64) (int64, float64, bool)
+	// setCreatedTimestamp records the created timestamp for the series
+	// referenced by ref, as observed independently of a sample value (e.g.
+	// Prometheus's "_created" series or OpenMetrics CT-in-CT). It is a
+	// no-op if created timestamps are disabled.
+	setCreatedTimestamp(ref uint64, ct int64)
+
+	// Get the reset timestamp and adjusted value for the input sample. ct
+	// is the created timestamp carried alongside the sample itself, if
+	// known (e.g. via PRW 2.0 metadata or OTLP start_time_unix_nano); pass
+	// 0 if unknown. ctSource labels the gcm_export_created_timestamp_used_total
+	// metric if ct ends up being used to pin an exact reset boundary; it's
+	// ignored otherwise. If false is returned, the sample should be skipped.
+	getResetAdjusted(ref uint64, t int64, v float64, ct int64, ctSource string) (int64, float64, bool)
 
 	// Attempt to set the new most recent time range for the series with given hash.
 	// Returns false if it failed, in which case the sample must be discarded.
@@ -62,6 +82,28 @@ type seriesCache struct {
 
 	// Function to retrieve external labels for the instance.
 	getExternalLabels func() labels.Labels
+
+	// Whether to use created timestamps, when known, to determine exact
+	// counter reset boundaries instead of always falling back to the
+	// t-1 heuristic in getResetAdjusted.
+	useCreatedTimestamps bool
+	// Counts samples for which a reset boundary was established, by source:
+	// a created timestamp (scrape or otlp) or the t-1 heuristic.
+	createdTimestampsUsed *prometheus.CounterVec
+}
+
+// seriesCacheOption configures optional behavior of a seriesCache.
+type seriesCacheOption func(*seriesCache)
+
+// WithCreatedTimestamps enables using created timestamps -- surfaced via
+// Prometheus scrape metadata (CT-in-CT) or OTLP's start_time_unix_nano on
+// cumulative points -- to pin exact counter reset boundaries instead of
+// unconditionally dropping the first sample of a series and guessing reset
+// boundaries afterwards.
+func WithCreatedTimestamps(enabled bool) seriesCacheOption {
+	return func(c *seriesCache) {
+		c.useCreatedTimestamps = enabled
+	}
 }
 
 type seriesCacheEntry struct {
@@ -85,6 +127,13 @@ type seriesCacheEntry struct {
 	hasReset       bool
 	resetValue     float64
 	resetTimestamp int64
+
+	// createdTimestamp is the most recently observed created timestamp for
+	// the series, if any is known. It predates the series' first sample by
+	// definition, so it doubles as an exact reset boundary; a change in its
+	// value between observations indicates the series' source process
+	// restarted and began a fresh cumulative from zero.
+	createdTimestamp int64
 }
 
 const (
@@ -111,17 +160,29 @@ func (e *seriesCacheEntry) setNextRefresh() {
 	e.nextRefresh = time.Now().Add(refreshInterval).Add(jitter).Unix()
 }
 
-func newSeriesCache(logger log.Logger, metricsPrefix string, getExternalLabels func() labels.Labels) *seriesCache {
+func newSeriesCache(logger log.Logger, metricsPrefix string, getExternalLabels func() labels.Labels, reg prometheus.Registerer, opts ...seriesCacheOption) *seriesCache {
 	if logger == nil {
 		logger = log.NewNopLogger()
 	}
-	return &seriesCache{
-		logger:            logger,
-		metricsPrefix:     metricsPrefix,
-		entries:           map[uint64]*seriesCacheEntry{},
-		intervals:         map[uint64]sampleInterval{},
-		getExternalLabels: getExternalLabels,
+	createdTimestampsUsed := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gcm_export_created_timestamp_used_total",
+		Help: "Number of samples for which a counter reset boundary was established, by source: a created timestamp from scrape or otlp, or the t-1 heuristic.",
+	}, []string{"source"})
+	if reg != nil {
+		reg.MustRegister(createdTimestampsUsed)
 	}
+	c := &seriesCache{
+		logger:                logger,
+		metricsPrefix:         metricsPrefix,
+		entries:               map[uint64]*seriesCacheEntry{},
+		intervals:             map[uint64]sampleInterval{},
+		getExternalLabels:     getExternalLabels,
+		createdTimestampsUsed: createdTimestampsUsed,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 func (c *seriesCache) run(ctx context.Context) {
@@ -152,7 +213,10 @@ func (c *seriesCache) invalidateAll() {
 }
 
 // garbageCollect drops obsolete cache entries based on the contents of the most
-// recent checkpoint.
+// recent checkpoint. Once implemented, dropping an entry also drops its
+// createdTimestamp, so a series reference reused for an unrelated new
+// series after GC starts from a clean reset state rather than inheriting
+// a stale one.
 func (c *seriesCache) garbageCollect() error {
 	level.Debug(c.logger).Log("msg", "garbage collection not implemented yet")
 	return nil
@@ -204,19 +268,56 @@ func (si *sampleInterval) accepts(start, end int64) bool {
 	return (start == si.start && end > si.end) || (start > si.start && start >= si.end)
 }
 
+// setCreatedTimestamp records the created timestamp for the series
+// referenced by ref. It is a no-op if created timestamps are disabled or
+// the series reference is unknown.
+func (c *seriesCache) setCreatedTimestamp(ref uint64, ct int64) {
+	if !c.useCreatedTimestamps {
+		return
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if e, ok := c.entries[ref]; ok {
+		e.createdTimestamp = ct
+	}
+}
+
 // getResetAdjusted takes a sample for a referenced series and returns
 // its reset timestamp and adjusted value.
 // If the last return argument is false, the sample should be dropped.
-func (c *seriesCache) getResetAdjusted(ref uint64, t int64, v float64) (int64, float64, bool) {
+func (c *seriesCache) getResetAdjusted(ref uint64, t int64, v float64, ct int64, ctSource string) (int64, float64, bool) {
+	if !c.useCreatedTimestamps {
+		ct = 0
+	}
 	c.mtx.Lock()
 	e, ok := c.entries[ref]
 	c.mtx.Unlock()
 	if !ok {
 		return 0, 0, false
 	}
+	// A created timestamp that differs from the one already on record means
+	// the series' source process restarted and began a new cumulative from
+	// zero -- the same situation the v < e.resetValue heuristic below exists
+	// to detect, just observed directly instead of inferred.
+	ctChanged := ct != 0 && e.createdTimestamp != 0 && ct != e.createdTimestamp
+	if ct != 0 {
+		e.createdTimestamp = ct
+	}
+
 	hasReset := e.hasReset
 	e.hasReset = true
 	if !hasReset {
+		if ct != 0 && ct < t {
+			// We know exactly when this cumulative began, so there's no
+			// need to drop the first sample and wait for a second one to
+			// establish a range: emit it directly against the known reset
+			// boundary.
+			e.resetTimestamp = ct
+			e.resetValue = 0
+			c.createdTimestampsUsed.WithLabelValues(ctSource).Inc()
+			return e.resetTimestamp, v, true
+		}
 		e.resetTimestamp = t
 		e.resetValue = v
 		// If we just initialized the reset timestamp, this sample should be skipped.
@@ -224,13 +325,21 @@ func (c *seriesCache) getResetAdjusted(ref uint64, t int64, v float64) (int64, f
 		// The next sample for will be considered from this point onwards.
 		return 0, 0, false
 	}
-	if v < e.resetValue {
-		// If the series was reset, set the reset timestamp to be one millisecond
-		// before the timestamp of the current sample.
-		// We don't know the true reset time but this ensures the range is non-zero
-		// while unlikely to conflict with any previous sample.
+	if v < e.resetValue || ctChanged {
 		e.resetValue = 0
-		e.resetTimestamp = t - 1
+		if ct != 0 && ct < t {
+			// Prefer the precise boundary the source told us about over the
+			// blind t-1 fallback.
+			e.resetTimestamp = ct
+			c.createdTimestampsUsed.WithLabelValues(ctSource).Inc()
+		} else {
+			// If the series was reset, set the reset timestamp to be one millisecond
+			// before the timestamp of the current sample.
+			// We don't know the true reset time but this ensures the range is non-zero
+			// while unlikely to conflict with any previous sample.
+			e.resetTimestamp = t - 1
+			c.createdTimestampsUsed.WithLabelValues(createdTimestampSourceHeuristic).Inc()
+		}
 	}
 	return e.resetTimestamp, v - e.resetValue, true
 }
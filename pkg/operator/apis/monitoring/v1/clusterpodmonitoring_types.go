@@ -0,0 +1,72 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterPodMonitoringSpec specifies how to scrape a set of pods across the cluster.
+type ClusterPodMonitoringSpec struct {
+	// Selector selects the pods to scrape.
+	Selector metav1.LabelSelector `json:"selector"`
+	// NamespaceSelector selects the namespaces to scrape pods from. If unset,
+	// all namespaces are considered.
+	// +optional
+	NamespaceSelector NamespaceSelector `json:"namespaceSelector,omitempty"`
+	// Endpoints to scrape on the selected pods.
+	Endpoints []ScrapeEndpoint `json:"endpoints"`
+
+	// TargetLabels lists Kubernetes metadata labels to attach as external
+	// labels on every series scraped from the selected pods.
+	// +optional
+	TargetLabels []string `json:"targetLabels,omitempty"`
+	// PodTargetLabels lists pod label keys to additionally attach as
+	// external labels on every series scraped from the selected pods.
+	// +optional
+	PodTargetLabels []string `json:"podTargetLabels,omitempty"`
+	// MetadataConfig configures which Kubernetes pod metadata to attach as
+	// external labels on scraped series.
+	// +optional
+	MetadataConfig *MetadataConfig `json:"metadataConfig,omitempty"`
+	// Lifecycle configures automatic staleness detection and garbage
+	// collection for this ClusterPodMonitoring.
+	// +optional
+	Lifecycle *LifecycleSpec `json:"lifecycle,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+
+// ClusterPodMonitoring defines monitoring for a set of pods across the cluster, allowing platform
+// admins to define fleet-wide scrape rules (e.g. kubelet, kube-state-metrics, node-exporter)
+// without creating a PodMonitoring in every namespace.
+type ClusterPodMonitoring struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterPodMonitoringSpec `json:"spec"`
+	Status MonitoringStatus         `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterPodMonitoringList is a list of ClusterPodMonitorings.
+type ClusterPodMonitoringList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterPodMonitoring `json:"items"`
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UnmanagedAnnotation, when set to "true" on a PodMonitoring or
+// ClusterPodMonitoring, tells the operator to skip reconciling the
+// resource, leaving any previously generated scrape configuration in
+// place. This allows temporarily pausing a resource (e.g. during a
+// migration) without deleting it.
+const UnmanagedAnnotation = "monitoring.googleapis.com/unmanaged"
+
+// IsUnmanaged reports whether obj carries the UnmanagedAnnotation with a
+// value of "true".
+func IsUnmanaged(obj metav1.Object) bool {
+	return obj.GetAnnotations()[UnmanagedAnnotation] == "true"
+}
+
+// ScrapeEndpoint specifies a Prometheus metrics endpoint to scrape.
+type ScrapeEndpoint struct {
+	// Name or number of the port to scrape.
+	Port string `json:"port,omitempty"`
+	// HTTP path from which to scrape for metrics. Defaults to "/metrics".
+	Path string `json:"path,omitempty"`
+	// Interval at which to scrape the endpoint. Defaults to "1m".
+	Interval string `json:"interval,omitempty"`
+	// Timeout for the scrape request.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// NamespaceSelector selects the namespaces to watch resources in, mirroring
+// the selector used by the Prometheus Operator for cluster-scoped resources.
+// If neither MatchNames nor MatchLabels is set, all namespaces are watched.
+type NamespaceSelector struct {
+	// MatchNames selects specific namespaces by name.
+	// +optional
+	MatchNames []string `json:"matchNames,omitempty"`
+	// MatchLabels selects namespaces whose labels match.
+	// +optional
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+}
+
+// MonitoringCondition describes a status condition of a monitoring resource.
+type MonitoringCondition struct {
+	Type               string                 `json:"type"`
+	Status             metav1.ConditionStatus `json:"status"`
+	LastTransitionTime metav1.Time            `json:"lastTransitionTime,omitempty"`
+	Reason             string                 `json:"reason,omitempty"`
+	Message            string                 `json:"message,omitempty"`
+}
+
+// MonitoringStatus is the common status shape shared by all monitoring.googleapis.com resources.
+type MonitoringStatus struct {
+	// ObservedGeneration is the generation of this resource last processed by the operator.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions holds the latest available observations of the resource's current state.
+	Conditions []MonitoringCondition `json:"conditions,omitempty"`
+	// Lifecycle reports the outcome of the most recent staleness rule
+	// evaluations, if spec.lifecycle is set.
+	// +optional
+	Lifecycle *LifecycleStatus `json:"lifecycle,omitempty"`
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LifecycleSpec configures automatic staleness detection for a
+// PodMonitoring/ClusterPodMonitoring resource. When set, the lifecycle
+// controller periodically evaluates DeletionRules against the managed
+// Prometheus and, once every rule has passed for enough consecutive
+// evaluations, garbage-collects the resource (or, in dry-run mode, only
+// records that it would have).
+type LifecycleSpec struct {
+	// DeletionRules are evaluated on every cycle. The resource becomes
+	// eligible for deletion once every rule evaluates to a non-empty
+	// instant vector for the configured number of consecutive cycles.
+	// +optional
+	DeletionRules []DeletionRule `json:"deletionRules,omitempty"`
+}
+
+// DeletionRule is a single staleness check. Exactly one of PromQL or
+// MetricRule must be set.
+type DeletionRule struct {
+	// PromQL is a raw PromQL expression that must evaluate to a non-empty
+	// instant vector for the resource to be considered eligible for
+	// deletion. The placeholders $JOB, $NAMESPACE, and $NAME are
+	// substituted with the scrape job name and the owning resource's
+	// namespace/name, e.g. `absent_over_time(up{job="$JOB"}[7d])`.
+	// +optional
+	PromQL string `json:"promql,omitempty"`
+	// MetricRule is a structured shorthand that the controller compiles
+	// into an equivalent PromQL expression. Mutually exclusive with PromQL.
+	// +optional
+	MetricRule *MetricRule `json:"metricRule,omitempty"`
+}
+
+// MetricRule is a structured shorthand for a common class of staleness
+// check: whether Metric, restricted to MatchLabels, has satisfied
+// Comparator Threshold throughout the last Window. For "==" and "!=" only
+// the value at evaluation time is compared; Window is not used.
+type MetricRule struct {
+	// Metric is the name of the metric to check.
+	Metric string `json:"metric"`
+	// MatchLabels further restricts the checked series. The placeholders
+	// $JOB, $NAMESPACE, and $NAME may be used as label values.
+	// +optional
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+	// Comparator is one of "<", "<=", ">", ">=", "==", "!=".
+	Comparator string `json:"comparator"`
+	// Threshold is the value Metric is compared against.
+	Threshold string `json:"threshold"`
+	// Window is how far back the comparison must hold throughout, e.g. "7d".
+	// Only the "<", "<=", ">" and ">=" comparators use it.
+	Window string `json:"window"`
+}
+
+// LifecycleStatus reports the outcome of the most recent lifecycle rule
+// evaluations for a PodMonitoring/ClusterPodMonitoring resource.
+type LifecycleStatus struct {
+	// Conditions holds the per-rule outcomes of the most recent evaluation.
+	// +optional
+	Conditions []MonitoringCondition `json:"conditions,omitempty"`
+	// ConsecutivePasses counts how many evaluation cycles in a row every
+	// rule in the spec has passed. Reset to 0 on any rule failure or
+	// evaluation error.
+	// +optional
+	ConsecutivePasses int32 `json:"consecutivePasses,omitempty"`
+	// LastEvaluationTime is when the deletion rules were last evaluated.
+	// +optional
+	LastEvaluationTime metav1.Time `json:"lastEvaluationTime,omitempty"`
+}
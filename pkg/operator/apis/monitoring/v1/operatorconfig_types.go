@@ -0,0 +1,326 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// +kubebuilder:object:root=true
+
+// OperatorConfig defines the configuration for the collection, rule-evaluation,
+// and export behavior of the operator. It is a singleton resource named
+// NameOperatorConfig in the operator's public namespace.
+type OperatorConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Collection configures collector-wide settings, such as kubelet scraping.
+	// +optional
+	Collection CollectionSpec `json:"collection,omitempty"`
+	// Rules configures the rule-evaluator.
+	// +optional
+	Rules RuleEvaluatorSpec `json:"rules,omitempty"`
+	// Features holds parameters for optional behavior that can be enabled.
+	// +optional
+	Features OperatorFeatures `json:"features,omitempty"`
+}
+
+// OperatorFeatures holds configuration for optional operator behavior.
+type OperatorFeatures struct {
+	// Config holds parameters related to the generated collector configuration.
+	// +optional
+	Config ConfigFeatures `json:"config,omitempty"`
+	// Lifecycle holds parameters for the PromQL-driven staleness controller
+	// that garbage-collects stale PodMonitoring/ClusterPodMonitoring
+	// resources declaring spec.lifecycle.
+	// +optional
+	Lifecycle LifecycleFeatures `json:"lifecycle,omitempty"`
+}
+
+// LifecycleFeatures configures the lifecycle staleness controller.
+type LifecycleFeatures struct {
+	// EvaluationInterval at which deletion rules are (re-)evaluated.
+	// Defaults to "1h".
+	// +optional
+	EvaluationInterval string `json:"evaluationInterval,omitempty"`
+	// RequiredConsecutivePasses is the number of consecutive evaluation
+	// cycles every deletion rule on a resource must pass before it is
+	// deleted. Must be at least 2, since a single evaluation is never
+	// sufficient. Defaults to 3.
+	// +optional
+	// +kubebuilder:validation:Minimum=2
+	RequiredConsecutivePasses int32 `json:"requiredConsecutivePasses,omitempty"`
+	// DryRun, if true, only records a DeletionEligible event and never
+	// actually deletes a resource once its rules have passed.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// ConfigFeatures holds parameters related to the generated collector configuration.
+type ConfigFeatures struct {
+	// Compression selects compression of the generated collector
+	// configuration. One of "none" or "gzip". Defaults to "none". The
+	// operator only validates the value; it does not compress any
+	// configuration for either value.
+	// +optional
+	// +kubebuilder:validation:Enum=none;gzip
+	Compression string `json:"compression,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OperatorConfigList is a list of OperatorConfigs.
+type OperatorConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OperatorConfig `json:"items"`
+}
+
+// CollectionSpec configures fleet-wide collector settings.
+type CollectionSpec struct {
+	// KubeletScraping configures scraping of the kubelet's built-in metrics endpoints.
+	// +optional
+	KubeletScraping *KubeletScraping `json:"kubeletScraping,omitempty"`
+}
+
+// KubeletScraping allows enabling scraping of kubelet metrics endpoints.
+type KubeletScraping struct {
+	// Interval at which to scrape the kubelet endpoints. Defaults to "1m".
+	Interval string `json:"interval"`
+}
+
+// RuleEvaluatorSpec configures the rule-evaluator deployment.
+type RuleEvaluatorSpec struct {
+	// ExternalLabels specifies external labels that are attached to any rule
+	// results and alerts produced by the rule-evaluator.
+	// +optional
+	ExternalLabels map[string]string `json:"externalLabels,omitempty"`
+	// QueryProjectID is the GCP project ID to evaluate rules against. If left
+	// empty, the project ID as configured for metric export is used.
+	// +optional
+	QueryProjectID string `json:"queryProjectId,omitempty"`
+	// GeneratorURL is the external URL added as a "Source" link to all alerts.
+	// +optional
+	GeneratorURL string `json:"generatorUrl,omitempty"`
+	// Credentials selects a key of a Secret containing a GCP service account
+	// credential JSON used to query and export metrics. If left empty, the
+	// in-cluster service account is used.
+	// +optional
+	Credentials *corev1.SecretKeySelector `json:"credentials,omitempty"`
+	// Alerting contains how the rule-evaluator configures alerting.
+	// +optional
+	Alerting AlertingSpec `json:"alerting,omitempty"`
+	// Replicas is the number of rule-evaluator replicas to run. Defaults to
+	// 1. The OperatorConfig validating webhook rejects values above 1.
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+}
+
+// AlertingSpec defines alerting configuration.
+type AlertingSpec struct {
+	// Alertmanagers to which the rule-evaluator sends firing alerts.
+	// +optional
+	Alertmanagers []AlertmanagerEndpoints `json:"alertmanagers,omitempty"`
+}
+
+// AlertmanagerEndpoints defines a selection of Alertmanager endpoints to
+// fire alerts against, discovered through the Kubernetes Endpoints API.
+type AlertmanagerEndpoints struct {
+	// Namespace of the Endpoints object.
+	Namespace string `json:"namespace"`
+	// Name of the Endpoints object.
+	Name string `json:"name"`
+	// Port on the Endpoints object on which Alertmanager is exposed.
+	Port intstr.IntOrString `json:"port"`
+	// Scheme to use when talking to Alertmanager.
+	// +optional
+	Scheme string `json:"scheme,omitempty"`
+	// PathPrefix to add in front of the generated paths.
+	// +optional
+	PathPrefix string `json:"pathPrefix,omitempty"`
+	// Timeout for requests to Alertmanager.
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+	// APIVersion of the Alertmanager API to use ("v1" or "v2").
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+	// Authorization configures the Authorization header sent to Alertmanager.
+	// +optional
+	Authorization *Authorization `json:"authorization,omitempty"`
+	// BasicAuth configures HTTP basic authentication credentials.
+	// +optional
+	BasicAuth *BasicAuth `json:"basicAuth,omitempty"`
+	// OAuth2 configures OAuth 2.0 client credentials flow authentication.
+	// +optional
+	OAuth2 *OAuth2 `json:"oauth2,omitempty"`
+	// SigV4 configures AWS SigV4-based request signing, e.g. for Amazon
+	// Managed Prometheus's Alertmanager. The OperatorConfig validating
+	// webhook rejects an Alertmanager that sets it, and the rule-evaluator
+	// does not sign requests with it.
+	// +optional
+	SigV4 *SigV4 `json:"sigv4,omitempty"`
+	// GoogleIAM configures authorization to Alertmanager using a GCP
+	// workload-identity-backed, audience-scoped Google-signed ID token, e.g.
+	// for an Alertmanager fronted by Identity-Aware Proxy. The OperatorConfig
+	// validating webhook rejects an Alertmanager that sets it, and the
+	// rule-evaluator does not mint or attach such a token.
+	// +optional
+	GoogleIAM *GoogleIAM `json:"googleIam,omitempty"`
+	// TLS configures the TLS settings used to connect to Alertmanager.
+	// +optional
+	TLS *TLSConfig `json:"tls,omitempty"`
+	// ProxyURL is an optional HTTP/HTTPS proxy to route requests through.
+	// +optional
+	ProxyURL string `json:"proxyUrl,omitempty"`
+	// FollowRedirects specifies whether the client should follow HTTP 3xx
+	// redirects. Defaults to true.
+	// +optional
+	FollowRedirects *bool `json:"followRedirects,omitempty"`
+	// Discovery optionally overrides how this Alertmanager's targets are
+	// found. If unset, Kubernetes Endpoints discovery against
+	// Namespace/Name/Port is used, matching prior behavior.
+	// +optional
+	Discovery *AlertmanagerDiscovery `json:"discovery,omitempty"`
+}
+
+// AlertmanagerDiscovery selects a non-Kubernetes way to discover
+// Alertmanager targets. Exactly one of Static or DNS should be set.
+type AlertmanagerDiscovery struct {
+	// Static lists Alertmanager targets directly as "host:port" addresses.
+	// +optional
+	Static *AlertmanagerStaticDiscovery `json:"static,omitempty"`
+	// DNS discovers Alertmanager targets through a DNS lookup.
+	// +optional
+	DNS *AlertmanagerDNSDiscovery `json:"dns,omitempty"`
+}
+
+// AlertmanagerStaticDiscovery is a fixed list of Alertmanager targets.
+type AlertmanagerStaticDiscovery struct {
+	// Targets is a list of "host:port" Alertmanager addresses.
+	Targets []string `json:"targets"`
+}
+
+// AlertmanagerDNSDiscovery resolves Alertmanager targets through a DNS lookup.
+type AlertmanagerDNSDiscovery struct {
+	// Name is the DNS name to resolve, e.g. "_web._tcp.alertmanager.example.com" for an SRV lookup.
+	Name string `json:"name"`
+	// Type is the DNS record type to query: "A", "AAAA", or "SRV". Defaults to "SRV".
+	// +optional
+	Type string `json:"type,omitempty"`
+	// Port is appended to resolved A/AAAA addresses. Ignored for SRV records,
+	// which carry their own port.
+	// +optional
+	Port int32 `json:"port,omitempty"`
+	// RefreshInterval at which to re-resolve the DNS name. Defaults to "30s".
+	// +optional
+	RefreshInterval string `json:"refreshInterval,omitempty"`
+}
+
+// BasicAuth configures HTTP basic authentication credentials.
+type BasicAuth struct {
+	// Username for basic authentication.
+	// +optional
+	Username string `json:"username,omitempty"`
+	// Password selects a key of a Secret containing the password.
+	// +optional
+	Password *corev1.SecretKeySelector `json:"password,omitempty"`
+}
+
+// OAuth2 configures an OAuth 2.0 client credentials grant used to
+// authenticate against Alertmanager.
+type OAuth2 struct {
+	// ClientID for the OAuth2 client credentials grant.
+	ClientID string `json:"clientId"`
+	// ClientSecret selects a key of a Secret containing the client secret.
+	ClientSecret *corev1.SecretKeySelector `json:"clientSecret"`
+	// TokenURL is the endpoint used to fetch the access token.
+	TokenURL string `json:"tokenUrl"`
+	// Scopes requested when fetching the access token.
+	// +optional
+	Scopes []string `json:"scopes,omitempty"`
+	// EndpointParams are additional parameters sent to the token endpoint.
+	// +optional
+	EndpointParams map[string]string `json:"endpointParams,omitempty"`
+}
+
+// SigV4 configures AWS Signature Version 4 request signing.
+type SigV4 struct {
+	// Region is the AWS region to sign for.
+	// +optional
+	Region string `json:"region,omitempty"`
+	// AccessKey selects a key of a Secret containing the AWS access key ID.
+	// +optional
+	AccessKey *corev1.SecretKeySelector `json:"accessKey,omitempty"`
+	// SecretKey selects a key of a Secret containing the AWS secret access key.
+	// +optional
+	SecretKey *corev1.SecretKeySelector `json:"secretKey,omitempty"`
+	// Profile is the named AWS profile used to authenticate.
+	// +optional
+	Profile string `json:"profile,omitempty"`
+	// RoleARN is the AWS role to assume for signing.
+	// +optional
+	RoleARN string `json:"roleArn,omitempty"`
+}
+
+// GoogleIAM configures authorization using a Google-signed ID token fetched
+// for the operator's GCP service account, scoped to Audience.
+type GoogleIAM struct {
+	// Audience the minted ID token is scoped to, typically the Alertmanager's
+	// IAP client ID.
+	Audience string `json:"audience"`
+}
+
+// Authorization configures HTTP Authorization header credentials.
+type Authorization struct {
+	// Type of the authorization scheme, e.g. "Bearer". Defaults to "Bearer".
+	// +optional
+	Type string `json:"type,omitempty"`
+	// Credentials selects a key of a Secret containing the credential value.
+	// +optional
+	Credentials *corev1.SecretKeySelector `json:"credentials,omitempty"`
+}
+
+// TLSConfig configures TLS settings for an HTTP client.
+type TLSConfig struct {
+	// CA selects a key that contains the CA certificate.
+	// +optional
+	CA *SecretOrConfigMap `json:"ca,omitempty"`
+	// Cert selects a key that contains the client certificate.
+	// +optional
+	Cert *SecretOrConfigMap `json:"cert,omitempty"`
+	// KeySecret selects a key of a Secret containing the client key.
+	// +optional
+	KeySecret *corev1.SecretKeySelector `json:"keySecret,omitempty"`
+	// ServerName overrides the server name used to verify the hostname on
+	// the returned certificate.
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+	// InsecureSkipVerify disables target certificate validation.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// SecretOrConfigMap selects a key from either a Secret or a ConfigMap.
+type SecretOrConfigMap struct {
+	// Secret selects a key of a Secret.
+	// +optional
+	Secret *corev1.SecretKeySelector `json:"secret,omitempty"`
+	// ConfigMap selects a key of a ConfigMap.
+	// +optional
+	ConfigMap *corev1.ConfigMapKeySelector `json:"configMap,omitempty"`
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PodMonitoringSpec specifies how to scrape a set of pods in the same namespace as the PodMonitoring.
+type PodMonitoringSpec struct {
+	// Selector selects the pods to scrape.
+	Selector metav1.LabelSelector `json:"selector"`
+	// Endpoints to scrape on the selected pods.
+	Endpoints []ScrapeEndpoint `json:"endpoints"`
+
+	// TargetLabels lists Kubernetes metadata labels to attach as external
+	// labels on every series scraped from the selected pods, sourced from
+	// the corresponding __meta_kubernetes_pod_* / __meta_kubernetes_node_*
+	// discovery labels (e.g. "node" or "pod_owner").
+	// +optional
+	TargetLabels []string `json:"targetLabels,omitempty"`
+	// PodTargetLabels lists pod label keys to additionally attach as
+	// external labels on every series scraped from the selected pods.
+	// +optional
+	PodTargetLabels []string `json:"podTargetLabels,omitempty"`
+	// MetadataConfig configures which Kubernetes pod metadata to attach as
+	// external labels on scraped series.
+	// +optional
+	MetadataConfig *MetadataConfig `json:"metadataConfig,omitempty"`
+	// Lifecycle configures automatic staleness detection and garbage
+	// collection for this PodMonitoring.
+	// +optional
+	Lifecycle *LifecycleSpec `json:"lifecycle,omitempty"`
+}
+
+// MetadataConfig configures the Kubernetes pod metadata attached to scraped series as external labels.
+type MetadataConfig struct {
+	// Node, if true, attaches the scraped pod's node name as an external label.
+	// +optional
+	Node bool `json:"node,omitempty"`
+	// NodeLabels lists node label keys to attach as external labels.
+	// +optional
+	NodeLabels []string `json:"nodeLabels,omitempty"`
+	// Owner, if true, attaches the scraped pod's controlling owner
+	// (e.g. Deployment or DaemonSet) as an external label.
+	// +optional
+	Owner bool `json:"owner,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// PodMonitoring defines monitoring for a set of pods in the same namespace as the PodMonitoring.
+type PodMonitoring struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PodMonitoringSpec `json:"spec"`
+	Status MonitoringStatus  `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PodMonitoringList is a list of PodMonitorings.
+type PodMonitoringList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PodMonitoring `json:"items"`
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ProbeSpec specifies a set of targets to probe through an external prober
+// such as blackbox_exporter, analogous to the upstream monitoring.coreos.com
+// Probe kind.
+type ProbeSpec struct {
+	// Prober specifies how to reach and query the prober that performs the
+	// actual probing of Targets.
+	Prober ProberSpec `json:"prober"`
+	// Module is the prober module to use, appended to the generated scrape
+	// config as the "module" parameter (__param_module).
+	// +optional
+	Module string `json:"module,omitempty"`
+	// Targets defines the targets to probe.
+	Targets ProbeTargets `json:"targets"`
+	// Interval at which to probe the targets. Defaults to "1m".
+	// +optional
+	Interval string `json:"interval,omitempty"`
+	// Timeout for the probe request.
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// ProberSpec describes how to reach the external prober.
+type ProberSpec struct {
+	// URL of the prober, e.g. "blackbox-exporter.monitoring.svc:9115".
+	URL string `json:"url"`
+	// Scheme to use when scraping the prober ("http" or "https"). Defaults to "http".
+	// +optional
+	Scheme string `json:"scheme,omitempty"`
+	// Path on the prober to which the probe requests are sent. Defaults to "/probe".
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// ProbeTargets defines the targets to be probed. Exactly one of StaticConfig
+// or Ingress should be set.
+type ProbeTargets struct {
+	// StaticConfig enumerates a fixed list of targets to probe.
+	// +optional
+	StaticConfig *ProbeTargetStaticConfig `json:"staticConfig,omitempty"`
+	// Ingress selects Ingress objects whose host/path combinations should be
+	// probed.
+	// +optional
+	Ingress *ProbeTargetIngress `json:"ingress,omitempty"`
+}
+
+// ProbeTargetStaticConfig is a static list of targets to probe, modeled
+// after the Prometheus static_config scrape target.
+type ProbeTargetStaticConfig struct {
+	// Targets is a list of probe targets, e.g. "example.com" or "https://example.com".
+	Targets []string `json:"targets"`
+	// Labels to attach to the generated series for these targets as external labels.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// ProbeTargetIngress selects Ingress objects whose host/path combinations
+// are expanded into probe targets.
+type ProbeTargetIngress struct {
+	// Selector selects the Ingress objects to probe.
+	// +optional
+	Selector metav1.LabelSelector `json:"selector,omitempty"`
+	// NamespaceSelector selects the namespaces to discover Ingress objects in.
+	// If unset, all namespaces are considered.
+	// +optional
+	NamespaceSelector NamespaceSelector `json:"namespaceSelector,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// Probe defines monitoring for a set of static or Ingress-derived targets
+// probed through an external prober such as blackbox_exporter.
+type Probe struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProbeSpec        `json:"spec"`
+	Status MonitoringStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ProbeList is a list of Probes.
+type ProbeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Probe `json:"items"`
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ServiceMonitoringSpec specifies how to discover and scrape a set of
+// Kubernetes Services in the same namespace as the ServiceMonitoring.
+//
+// Unlike PodMonitoring, which is translated to a Prometheus
+// kubernetes_sd_configs role: pod, ServiceMonitoring is translated to
+// role: endpoints, so targets carry the resolved Service's discovery
+// metadata rather than only the backing Pod's. This mirrors
+// prometheus-operator's ServiceMonitor and gives users with existing
+// ServiceMonitor manifests a migration path onto this operator.
+type ServiceMonitoringSpec struct {
+	// Selector selects the Services to scrape.
+	Selector metav1.LabelSelector `json:"selector"`
+	// Endpoints to scrape on the selected Services. Each entry's Port must
+	// name a port declared on the selected Service, since role: endpoints
+	// discovery resolves ports by name rather than by number.
+	Endpoints []ScrapeEndpoint `json:"endpoints"`
+
+	// TargetLabels lists Kubernetes metadata labels to attach as external
+	// labels on every series scraped through the selected Services, sourced
+	// from the corresponding __meta_kubernetes_service_* discovery labels
+	// (e.g. "service" or "service_label_app").
+	// +optional
+	TargetLabels []string `json:"targetLabels,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// ServiceMonitoring defines monitoring for a set of Services in the same
+// namespace as the ServiceMonitoring.
+type ServiceMonitoring struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ServiceMonitoringSpec `json:"spec"`
+	Status MonitoringStatus      `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ServiceMonitoringList is a list of ServiceMonitorings.
+type ServiceMonitoringList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ServiceMonitoring `json:"items"`
+}
+
+// ClusterServiceMonitoringSpec specifies how to discover and scrape a set of
+// Kubernetes Services across all namespaces.
+type ClusterServiceMonitoringSpec struct {
+	// Selector selects the Services to scrape.
+	Selector metav1.LabelSelector `json:"selector"`
+	// NamespaceSelector selects the namespaces to discover Services in. If
+	// neither MatchNames nor MatchLabels is set, Services in all namespaces
+	// are considered.
+	// +optional
+	NamespaceSelector NamespaceSelector `json:"namespaceSelector,omitempty"`
+	// Endpoints to scrape on the selected Services. Each entry's Port must
+	// name a port declared on the selected Service.
+	Endpoints []ScrapeEndpoint `json:"endpoints"`
+	// TargetLabels lists Kubernetes metadata labels to attach as external
+	// labels on every series scraped through the selected Services.
+	// +optional
+	TargetLabels []string `json:"targetLabels,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// ClusterServiceMonitoring defines monitoring for a set of Services across
+// all namespaces.
+type ClusterServiceMonitoring struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterServiceMonitoringSpec `json:"spec"`
+	Status MonitoringStatus             `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterServiceMonitoringList is a list of ClusterServiceMonitorings.
+type ClusterServiceMonitoringList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterServiceMonitoring `json:"items"`
+}
+
+// ServiceMonitoringResource returns the GroupVersionResource identifying the
+// ServiceMonitoring CRD, used to build its webhook paths.
+func ServiceMonitoringResource() metav1.GroupVersionResource {
+	return metav1.GroupVersionResource{
+		Group:    SchemeGroupVersion.Group,
+		Version:  SchemeGroupVersion.Version,
+		Resource: "servicemonitorings",
+	}
+}
+
+// ClusterServiceMonitoringResource returns the GroupVersionResource
+// identifying the ClusterServiceMonitoring CRD, used to build its webhook
+// paths.
+func ClusterServiceMonitoringResource() metav1.GroupVersionResource {
+	return metav1.GroupVersionResource{
+		Group:    SchemeGroupVersion.Group,
+		Version:  SchemeGroupVersion.Version,
+		Resource: "clusterservicemonitorings",
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/util/cert"
+)
+
+const (
+	// certRenewalThreshold is how far ahead of expiry a self-signed serving
+	// certificate is renewed.
+	certRenewalThreshold = 30 * 24 * time.Hour
+	// certCheckInterval is how often a self-signed certificate's remaining
+	// validity is checked.
+	certCheckInterval = time.Hour
+)
+
+// certHolder atomically holds the webhook server's current serving
+// certificate so rotated material can be swapped in without restarting the
+// process, mirroring the role dynamiccertificates.CertKeyContentProvider
+// plays for apiserver-style components.
+type certHolder struct {
+	v atomic.Value // stores *tls.Certificate
+}
+
+func (h *certHolder) set(crt, key []byte) error {
+	pair, err := tls.X509KeyPair(crt, key)
+	if err != nil {
+		return errors.Wrap(err, "parse TLS key pair")
+	}
+	h.v.Store(&pair)
+	return nil
+}
+
+// GetCertificate implements the tls.Config.GetCertificate hook.
+func (h *certHolder) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c, _ := h.v.Load().(*tls.Certificate)
+	if c == nil {
+		return nil, errors.New("no TLS certificate loaded yet")
+	}
+	return c, nil
+}
+
+// withGetCertificate installs h as the webhook server's certificate source,
+// taking over from controller-runtime's static file lookup so in-process
+// rotation takes effect immediately.
+func (h *certHolder) withGetCertificate(c *tls.Config) {
+	c.GetCertificate = h.GetCertificate
+}
+
+// runCertManager writes the initial serving certificate under dir, loads it
+// into holder, and keeps it current for the life of ctx:
+//
+//   - if opts.TLSCertFile/TLSKeyFile point at a mounted cert (e.g. one
+//     maintained by cert-manager or GKE), it is reloaded via fsnotify
+//     whenever the files change;
+//   - otherwise, the self-signed pair generated here is renewed once its
+//     remaining validity drops below certRenewalThreshold.
+//
+// onRotate is invoked with the new CA bundle immediately after each
+// successful rotation, so ValidatingWebhookConfiguration/
+// MutatingWebhookConfiguration can be repatched without waiting for the
+// minute-long polling loop in setupAdmissionWebhooks.
+func (o *Operator) runCertManager(ctx context.Context, dir string, holder *certHolder, onRotate func(caBundle []byte)) ([]byte, error) {
+	caBundle, err := o.writeCerts(dir, holder)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.opts.TLSCertFile != "" {
+		go o.watchMountedCerts(ctx, dir, holder, onRotate)
+	} else if o.opts.TLSCert == "" {
+		go o.rotateSelfSignedCert(ctx, dir, holder, onRotate)
+	}
+	return caBundle, nil
+}
+
+// writeCerts resolves the serving cert/key pair (mounted, explicitly
+// provided, or freshly self-signed), writes it to dir, and loads it into
+// holder. It returns the CA bundle to inject into the webhook configurations.
+func (o *Operator) writeCerts(dir string, holder *certHolder) ([]byte, error) {
+	crt, key, caData, err := o.resolveCertKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	if err := atomicWriteFile(filepath.Join(dir, "tls.crt"), crt); err != nil {
+		return nil, errors.Wrap(err, "write cert file")
+	}
+	if err := atomicWriteFile(filepath.Join(dir, "tls.key"), key); err != nil {
+		return nil, errors.Wrap(err, "write key file")
+	}
+	if err := holder.set(crt, key); err != nil {
+		return nil, err
+	}
+	return caData, nil
+}
+
+// resolveCertKeyPair produces the cert/key/CA bytes to serve, split out from
+// writeCerts so runCertManager can call it again on each rotation.
+func (o *Operator) resolveCertKeyPair() (crt, key, caData []byte, err error) {
+	switch {
+	case o.opts.TLSCertFile != "":
+		crt, err = ioutil.ReadFile(o.opts.TLSCertFile)
+		if err != nil {
+			return nil, nil, nil, errors.Wrap(err, "read TLS certificate file")
+		}
+		key, err = ioutil.ReadFile(o.opts.TLSKeyFile)
+		if err != nil {
+			return nil, nil, nil, errors.Wrap(err, "read TLS key file")
+		}
+		if o.opts.CACert != "" {
+			caData, err = base64.StdEncoding.DecodeString(o.opts.CACert)
+			if err != nil {
+				return nil, nil, nil, errors.Wrap(err, "decoding certificate authority")
+			}
+		} else {
+			caData = crt
+		}
+	case o.opts.TLSCert != "" || o.opts.TLSKey != "":
+		crt, err = base64.StdEncoding.DecodeString(o.opts.TLSCert)
+		if err != nil {
+			return nil, nil, nil, errors.Wrap(err, "decoding TLS certificate")
+		}
+		key, err = base64.StdEncoding.DecodeString(o.opts.TLSKey)
+		if err != nil {
+			return nil, nil, nil, errors.Wrap(err, "decoding TLS key")
+		}
+		if o.opts.CACert != "" {
+			caData, err = base64.StdEncoding.DecodeString(o.opts.CACert)
+			if err != nil {
+				return nil, nil, nil, errors.Wrap(err, "decoding certificate authority")
+			}
+		}
+	default:
+		// Generate a self-signed pair, valid for 1 year.
+		fqdn := fmt.Sprintf("%s.%s.svc", NameOperator, o.opts.OperatorNamespace)
+
+		crt, key, err = cert.GenerateSelfSignedCertKey(fqdn, nil, nil)
+		if err != nil {
+			return nil, nil, nil, errors.Wrap(err, "generate self-signed TLS key pair")
+		}
+		// Use crt as the ca in the self-signed case.
+		caData = crt
+	}
+	return crt, key, caData, nil
+}
+
+// rotateSelfSignedCert periodically renews the self-signed serving
+// certificate written by writeCerts once its remaining validity drops below
+// certRenewalThreshold.
+func (o *Operator) rotateSelfSignedCert(ctx context.Context, dir string, holder *certHolder, onRotate func(caBundle []byte)) {
+	ticker := time.NewTicker(certCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		renew, err := o.selfSignedCertNeedsRenewal(dir)
+		if err != nil {
+			o.logger.Error(err, "checking self-signed certificate expiry failed")
+			continue
+		}
+		if !renew {
+			continue
+		}
+		o.logger.Info("renewing self-signed webhook serving certificate")
+
+		caBundle, err := o.writeCerts(dir, holder)
+		if err != nil {
+			o.logger.Error(err, "renewing self-signed webhook serving certificate failed")
+			continue
+		}
+		onRotate(caBundle)
+	}
+}
+
+// selfSignedCertNeedsRenewal reports whether the cert at dir/tls.crt has
+// less than certRenewalThreshold of validity remaining.
+func (o *Operator) selfSignedCertNeedsRenewal(dir string) (bool, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, "tls.crt"))
+	if err != nil {
+		return false, errors.Wrap(err, "read current cert file")
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return true, nil
+	}
+	crt, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false, errors.Wrap(err, "parse current cert file")
+	}
+	return time.Until(crt.NotAfter) < certRenewalThreshold, nil
+}
+
+// watchMountedCerts reloads the serving certificate whenever the mounted
+// cert/key files configured via opts.TLSCertFile/TLSKeyFile change on disk,
+// e.g. when cert-manager or a GKE-managed Secret rotates them in place.
+func (o *Operator) watchMountedCerts(ctx context.Context, dir string, holder *certHolder, onRotate func(caBundle []byte)) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		o.logger.Error(err, "creating file watcher for mounted TLS certificate failed")
+		return
+	}
+	defer watcher.Close()
+
+	// Watch the parent directories rather than the files themselves: Secret
+	// volume mounts are updated by swapping a symlinked directory, which
+	// doesn't preserve a watch on the old file.
+	watched := map[string]bool{}
+	for _, f := range []string{o.opts.TLSCertFile, o.opts.TLSKeyFile} {
+		d := filepath.Dir(f)
+		if watched[d] {
+			continue
+		}
+		if err := watcher.Add(d); err != nil {
+			o.logger.Error(err, "watching TLS certificate directory failed", "dir", d)
+			return
+		}
+		watched[d] = true
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-watcher.Errors:
+			o.logger.Error(err, "watching mounted TLS certificate failed")
+		case <-watcher.Events:
+			caBundle, err := o.writeCerts(dir, holder)
+			if err != nil {
+				o.logger.Error(err, "reloading mounted TLS certificate failed")
+				continue
+			}
+			o.logger.Info("reloaded mounted webhook serving certificate")
+			onRotate(caBundle)
+		}
+	}
+}
+
+// atomicWriteFile writes data to a temporary file in the same directory as
+// path and renames it into place, so concurrent readers never observe a
+// partially-written certificate or key.
+func atomicWriteFile(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
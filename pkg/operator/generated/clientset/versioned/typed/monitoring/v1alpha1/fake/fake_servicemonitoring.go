@@ -100,6 +100,18 @@ func (c *FakeServiceMonitorings) Update(ctx context.Context, serviceMonitoring *
 	return obj.(*v1alpha1.ServiceMonitoring), err
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeServiceMonitorings) UpdateStatus(ctx context.Context, serviceMonitoring *v1alpha1.ServiceMonitoring, opts v1.UpdateOptions) (*v1alpha1.ServiceMonitoring, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(servicemonitoringsResource, "status", c.ns, serviceMonitoring), &v1alpha1.ServiceMonitoring{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ServiceMonitoring), err
+}
+
 // Delete takes name of the serviceMonitoring and deletes it. Returns an error if one occurs.
 func (c *FakeServiceMonitorings) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	_, err := c.Fake.
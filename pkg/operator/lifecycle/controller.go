@@ -0,0 +1,353 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	prommodel "github.com/prometheus/common/model"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+// ReasonDeletionEligible is the event reason emitted (in DryRun mode) once a
+// resource has passed its deletion rules for RequiredConsecutivePasses
+// consecutive evaluations.
+const ReasonDeletionEligible = "DeletionEligible"
+
+// defaultEvaluationInterval is used whenever the singleton OperatorConfig
+// doesn't specify Features.Lifecycle.EvaluationInterval.
+const defaultEvaluationInterval = time.Hour
+
+// minRequiredConsecutivePasses is the smallest allowed
+// Features.Lifecycle.RequiredConsecutivePasses: a single evaluation is never
+// enough to delete a resource, so at least one repeat is always required.
+const minRequiredConsecutivePasses = 2
+
+// defaultRequiredConsecutivePasses is used whenever the singleton
+// OperatorConfig doesn't specify Features.Lifecycle.RequiredConsecutivePasses.
+const defaultRequiredConsecutivePasses = 3
+
+// Options configures the lifecycle Controller.
+type Options struct {
+	// PublicNamespace is where the singleton OperatorConfig lives.
+	PublicNamespace string
+	// OperatorConfigName is the name of the singleton OperatorConfig.
+	OperatorConfigName string
+	// QueryURL is the base URL of the Prometheus-compatible HTTP API the
+	// controller evaluates deletion rules against, typically the in-cluster
+	// rule-evaluator.
+	QueryURL string
+}
+
+// Controller periodically evaluates DeletionRules declared on
+// PodMonitoring/ClusterPodMonitoring resources and garbage-collects (or, in
+// dry-run mode, flags) those that have gone stale.
+//
+// It implements controller-runtime's manager.Runnable interface so it can be
+// registered with mgr.Add alongside the reconciler-based controllers.
+type Controller struct {
+	client   client.Client
+	api      promv1.API
+	recorder record.EventRecorder
+	logger   logr.Logger
+	opts     Options
+
+	evalErrors prometheus.Counter
+	lastRunAt  time.Time
+}
+
+// NewController builds a lifecycle Controller that queries opts.QueryURL to
+// evaluate deletion rules.
+func NewController(c client.Client, recorder record.EventRecorder, logger logr.Logger, reg prometheus.Registerer, opts Options) (*Controller, error) {
+	apiClient, err := api.NewClient(api.Config{Address: opts.QueryURL})
+	if err != nil {
+		return nil, errors.Wrap(err, "create Prometheus API client")
+	}
+
+	evalErrors := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "gmp_operator_lifecycle_rule_evaluation_errors_total",
+		Help: "Number of errors encountered compiling or evaluating lifecycle deletion rules.",
+	})
+	if reg != nil {
+		reg.MustRegister(evalErrors)
+	}
+
+	return &Controller{
+		client:     c,
+		api:        promv1.NewAPI(apiClient),
+		recorder:   recorder,
+		logger:     logger.WithName("lifecycle"),
+		opts:       opts,
+		evalErrors: evalErrors,
+	}, nil
+}
+
+// Start implements manager.Runnable. It blocks, periodically enumerating
+// eligible objects, until ctx is cancelled.
+//
+// A short, fixed poll period is used to check whether the
+// Features.Lifecycle.EvaluationInterval configured on the singleton
+// OperatorConfig has elapsed since the last run, so that changing the
+// configured interval takes effect without restarting the operator.
+func (c *Controller) Start(ctx context.Context) error {
+	const pollPeriod = time.Minute
+
+	ticker := time.NewTicker(pollPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		interval, requiredPasses, dryRun, err := c.loadConfig(ctx)
+		if err != nil {
+			c.logger.Error(err, "loading lifecycle configuration from OperatorConfig failed")
+			continue
+		}
+		if time.Since(c.lastRunAt) < interval {
+			continue
+		}
+		c.lastRunAt = time.Now()
+
+		if err := c.runOnce(ctx, requiredPasses, dryRun); err != nil {
+			c.logger.Error(err, "lifecycle evaluation cycle failed")
+		}
+	}
+}
+
+// loadConfig reads Features.Lifecycle off the singleton OperatorConfig,
+// applying defaults for anything left unset.
+func (c *Controller) loadConfig(ctx context.Context) (interval time.Duration, requiredPasses int32, dryRun bool, err error) {
+	var oc monitoringv1.OperatorConfig
+	key := types.NamespacedName{Namespace: c.opts.PublicNamespace, Name: c.opts.OperatorConfigName}
+	if err := c.client.Get(ctx, key, &oc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return defaultEvaluationInterval, defaultRequiredConsecutivePasses, false, nil
+		}
+		return 0, 0, false, errors.Wrap(err, "get OperatorConfig")
+	}
+
+	lc := oc.Features.Lifecycle
+
+	interval = defaultEvaluationInterval
+	if lc.EvaluationInterval != "" {
+		d, err := prommodel.ParseDuration(lc.EvaluationInterval)
+		if err != nil {
+			return 0, 0, false, errors.Wrap(err, "invalid features.lifecycle.evaluationInterval")
+		}
+		interval = time.Duration(d)
+	}
+
+	requiredPasses = defaultRequiredConsecutivePasses
+	if lc.RequiredConsecutivePasses != 0 {
+		requiredPasses = lc.RequiredConsecutivePasses
+	}
+	if requiredPasses < minRequiredConsecutivePasses {
+		return 0, 0, false, errors.Errorf("features.lifecycle.requiredConsecutivePasses must be at least %d; a single evaluation is never sufficient to delete a resource", minRequiredConsecutivePasses)
+	}
+
+	return interval, requiredPasses, lc.DryRun, nil
+}
+
+// runOnce evaluates deletion rules for every PodMonitoring/ClusterPodMonitoring
+// that declares spec.lifecycle, updating status.lifecycle and
+// deleting/flagging resources that have passed for requiredPasses
+// consecutive cycles.
+func (c *Controller) runOnce(ctx context.Context, requiredPasses int32, dryRun bool) error {
+	var pms monitoringv1.PodMonitoringList
+	if err := c.client.List(ctx, &pms); err != nil {
+		return errors.Wrap(err, "list PodMonitorings")
+	}
+	for i := range pms.Items {
+		pm := &pms.Items[i]
+		if pm.Spec.Lifecycle == nil || len(pm.Spec.Lifecycle.DeletionRules) == 0 {
+			continue
+		}
+		job := defaultJobName("PodMonitoring", pm.Namespace, pm.Name)
+		c.evaluate(ctx, pm, pm.Namespace, pm.Name, job, pm.Spec.Lifecycle.DeletionRules, &pm.Status, requiredPasses, dryRun)
+	}
+
+	var cpms monitoringv1.ClusterPodMonitoringList
+	if err := c.client.List(ctx, &cpms); err != nil {
+		return errors.Wrap(err, "list ClusterPodMonitorings")
+	}
+	for i := range cpms.Items {
+		cpm := &cpms.Items[i]
+		if cpm.Spec.Lifecycle == nil || len(cpm.Spec.Lifecycle.DeletionRules) == 0 {
+			continue
+		}
+		job := defaultJobName("ClusterPodMonitoring", "", cpm.Name)
+		// ClusterPodMonitoring is cluster-scoped, so there's no namespace to
+		// constrain results to.
+		c.evaluate(ctx, cpm, "", cpm.Name, job, cpm.Spec.Lifecycle.DeletionRules, &cpm.Status, requiredPasses, dryRun)
+	}
+	return nil
+}
+
+// defaultJobName returns the scrape job name a PodMonitoring/ClusterPodMonitoring
+// would be exposed under.
+//
+// TODO(pintohutch): this snapshot has no collector config generator (the
+// PodMonitoring/ClusterPodMonitoring selection pipeline that would emit the
+// actual `job` label scrape targets carry), so this is a best-effort
+// placeholder. It must be reconciled with whatever naming scheme that
+// generator adopts once it exists.
+func defaultJobName(kind, namespace, name string) string {
+	if namespace == "" {
+		return fmt.Sprintf("%s/%s", kind, name)
+	}
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+// evaluate runs obj's deletion rules, persists the outcome to status, and
+// deletes (or, in dry-run mode, flags) obj once it has passed for
+// requiredPasses consecutive cycles.
+func (c *Controller) evaluate(ctx context.Context, obj client.Object, namespace, name, job string, rules []monitoringv1.DeletionRule, status *monitoringv1.MonitoringStatus, requiredPasses int32, dryRun bool) {
+	allPass, conditions := c.evaluateRules(ctx, namespace, name, job, rules)
+
+	if status.Lifecycle == nil {
+		status.Lifecycle = &monitoringv1.LifecycleStatus{}
+	}
+	status.Lifecycle.Conditions = conditions
+	status.Lifecycle.LastEvaluationTime = metav1.Now()
+	if allPass {
+		status.Lifecycle.ConsecutivePasses++
+	} else {
+		status.Lifecycle.ConsecutivePasses = 0
+	}
+
+	if err := c.client.Status().Update(ctx, obj); err != nil {
+		c.logger.Error(err, "updating lifecycle status failed", "namespace", namespace, "name", name)
+		return
+	}
+
+	if status.Lifecycle.ConsecutivePasses < requiredPasses {
+		return
+	}
+
+	if dryRun {
+		if c.recorder != nil {
+			c.recorder.Eventf(obj, "Normal", ReasonDeletionEligible,
+				"all deletion rules have passed for %d consecutive evaluations; would be deleted outside dry-run mode", status.Lifecycle.ConsecutivePasses)
+		}
+		return
+	}
+
+	if err := c.client.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+		c.logger.Error(err, "deleting stale resource failed", "namespace", namespace, "name", name)
+	}
+}
+
+// evaluateRules queries c.api for each rule and reports whether all of them
+// passed, along with a MonitoringCondition per rule recording the outcome.
+func (c *Controller) evaluateRules(ctx context.Context, namespace, name, job string, rules []monitoringv1.DeletionRule) (bool, []monitoringv1.MonitoringCondition) {
+	v := vars{job: job, namespace: namespace, name: name}
+	allPass := true
+	conditions := make([]monitoringv1.MonitoringCondition, 0, len(rules))
+
+	for i, rule := range rules {
+		condType := fmt.Sprintf("DeletionRule%d", i)
+
+		expr, err := compileRule(rule, v)
+		if err != nil {
+			c.evalErrors.Inc()
+			allPass = false
+			conditions = append(conditions, failCondition(condType, "InvalidRule", err.Error()))
+			continue
+		}
+
+		val, warnings, err := c.api.Query(ctx, expr, time.Now())
+		if len(warnings) > 0 {
+			c.logger.Info("lifecycle rule evaluation returned warnings", "namespace", namespace, "name", name, "expr", expr, "warnings", warnings)
+		}
+		if err != nil {
+			c.evalErrors.Inc()
+			allPass = false
+			conditions = append(conditions, failCondition(condType, "QueryError", err.Error()))
+			continue
+		}
+
+		vec, ok := val.(prommodel.Vector)
+		if !ok {
+			c.evalErrors.Inc()
+			allPass = false
+			conditions = append(conditions, failCondition(condType, "UnexpectedResultType", fmt.Sprintf("expected an instant vector, got %s", val.Type())))
+			continue
+		}
+
+		if namespace != "" {
+			if err := requireSingleNamespace(vec, namespace); err != nil {
+				c.evalErrors.Inc()
+				allPass = false
+				conditions = append(conditions, failCondition(condType, "MultiNamespaceResult", err.Error()))
+				continue
+			}
+		}
+
+		pass := len(vec) > 0
+		status := metav1.ConditionFalse
+		if pass {
+			status = metav1.ConditionTrue
+		} else {
+			allPass = false
+		}
+		conditions = append(conditions, monitoringv1.MonitoringCondition{
+			Type:               condType,
+			Status:             status,
+			Reason:             "Evaluated",
+			Message:            expr,
+			LastTransitionTime: metav1.Now(),
+		})
+	}
+	return allPass, conditions
+}
+
+// requireSingleNamespace refuses a result vector that contains series for a
+// namespace other than namespace, e.g. from a PromQL expression that dropped
+// the namespace label via aggregation.
+func requireSingleNamespace(vec prommodel.Vector, namespace string) error {
+	for _, s := range vec {
+		if ns, ok := s.Metric["namespace"]; ok && string(ns) != namespace {
+			return errors.Errorf("result contains series for namespace %q, expected only %q", ns, namespace)
+		}
+	}
+	return nil
+}
+
+func failCondition(condType, reason, message string) monitoringv1.MonitoringCondition {
+	return monitoringv1.MonitoringCondition{
+		Type:               condType,
+		Status:             metav1.ConditionUnknown,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	}
+}
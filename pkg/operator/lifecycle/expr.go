@@ -0,0 +1,125 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lifecycle periodically evaluates user-authored PromQL-based
+// staleness rules against the managed Prometheus to decide when a
+// PodMonitoring/ClusterPodMonitoring has gone stale and should be
+// garbage-collected or, in dry-run mode, flagged for human review with a
+// DeletionEligible event. Stale resources are never paused.
+package lifecycle
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+// vars identifies the monitoring resource a rule is evaluated for, used to
+// substitute $JOB, $NAMESPACE, and $NAME placeholders.
+type vars struct {
+	job       string
+	namespace string
+	name      string
+}
+
+func (v vars) substitute(s string) string {
+	return strings.NewReplacer(
+		"$JOB", v.job,
+		"$NAMESPACE", v.namespace,
+		"$NAME", v.name,
+	).Replace(s)
+}
+
+// compileRule returns the PromQL expression for rule with $JOB, $NAMESPACE,
+// and $NAME substituted from v. Exactly one of rule.PromQL or
+// rule.MetricRule must be set.
+func compileRule(rule monitoringv1.DeletionRule, v vars) (string, error) {
+	switch {
+	case rule.PromQL != "" && rule.MetricRule != nil:
+		return "", fmt.Errorf("at most one of promql and metricRule may be set")
+	case rule.PromQL != "":
+		return v.substitute(rule.PromQL), nil
+	case rule.MetricRule != nil:
+		return compileMetricRule(rule.MetricRule, v)
+	default:
+		return "", fmt.Errorf("one of promql and metricRule must be set")
+	}
+}
+
+var validComparators = map[string]bool{
+	"<": true, "<=": true, ">": true, ">=": true, "==": true, "!=": true,
+}
+
+// compileMetricRule compiles a MetricRule shorthand into a PromQL
+// expression. For the inequality comparators, it asserts the comparison
+// held throughout the entire window by aggregating with max_over_time (for
+// "<"/"<=") or min_over_time (for ">"/">="), and additionally requires every
+// series matched by the selector to satisfy the comparison: the metric
+// selector commonly matches more than one series (one per pod instance,
+// say), and a result is only meaningful as "eligible" if none of them are
+// still violating the rule, not merely if at least one of them is.
+func compileMetricRule(m *monitoringv1.MetricRule, v vars) (string, error) {
+	if m.Metric == "" {
+		return "", fmt.Errorf("metricRule.metric must be set")
+	}
+	if !validComparators[m.Comparator] {
+		return "", fmt.Errorf("metricRule.comparator %q must be one of <, <=, >, >=, ==, !=", m.Comparator)
+	}
+	if m.Window == "" {
+		return "", fmt.Errorf("metricRule.window must be set")
+	}
+	if m.Threshold == "" {
+		return "", fmt.Errorf("metricRule.threshold must be set")
+	}
+
+	selector := m.Metric
+	if len(m.MatchLabels) > 0 {
+		keys := make([]string, 0, len(m.MatchLabels))
+		for k := range m.MatchLabels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		matchers := make([]string, 0, len(keys))
+		for _, k := range keys {
+			matchers = append(matchers, fmt.Sprintf("%s=%q", k, v.substitute(m.MatchLabels[k])))
+		}
+		selector = fmt.Sprintf("%s{%s}", m.Metric, strings.Join(matchers, ","))
+	}
+
+	switch m.Comparator {
+	case "<", "<=":
+		agg := fmt.Sprintf("max_over_time(%s[%s])", selector, m.Window)
+		return requireAllSeries(agg, m.Comparator, m.Threshold), nil
+	case ">", ">=":
+		agg := fmt.Sprintf("min_over_time(%s[%s])", selector, m.Window)
+		return requireAllSeries(agg, m.Comparator, m.Threshold), nil
+	default:
+		// TODO(pintohutch): no single _over_time aggregate can soundly assert
+		// "every sample in the window was/wasn't exactly Threshold", so ==
+		// and != only compare the instant value at evaluation time rather
+		// than holding over Window.
+		return requireAllSeries(selector, m.Comparator, m.Threshold), nil
+	}
+}
+
+// requireAllSeries wraps a PromQL expr with a count comparison so the
+// resulting expression is only non-empty when every series matched by expr
+// satisfies "expr comparator threshold", rather than when at least one of
+// them does.
+func requireAllSeries(expr, comparator, threshold string) string {
+	return fmt.Sprintf("count(%s) == count(%s %s %s)", expr, expr, comparator, threshold)
+}
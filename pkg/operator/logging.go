@@ -0,0 +1,33 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"net/http"
+
+	"k8s.io/apiserver/pkg/server/routes"
+	"k8s.io/component-base/logs"
+)
+
+// InstallDebugFlagsHandler registers the standard "GET/PUT /debug/flags/v"
+// endpoint on mux, letting operators raise or lower log verbosity at
+// runtime without restarting the operator.
+//
+// TODO(pintohutch): this snapshot has no cmd/operator entrypoint, so nothing
+// currently calls this. It should be invoked on the same mux that serves
+// /metrics, once that entrypoint exists.
+func InstallDebugFlagsHandler(mux *http.ServeMux) {
+	routes.DebugFlags{}.Install(mux, "v", routes.StringFlagSetterFunc(logs.GlogSetter))
+}
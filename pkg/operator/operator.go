@@ -16,11 +16,9 @@ package operator
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
 	"io/ioutil"
 	"net"
-	"path/filepath"
 	"strconv"
 	"time"
 
@@ -33,10 +31,14 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	runtimeutil "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/util/cert"
 	"k8s.io/client-go/util/workqueue"
+	logsapiv1 "k8s.io/component-base/logs/api/v1"
+	// Registers the "json" value for --logging-format, required for
+	// LoggingConfig.Format to be honored end-to-end.
+	_ "k8s.io/component-base/logs/json/register"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -45,6 +47,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+	"github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/lifecycle"
 )
 
 const (
@@ -94,10 +97,14 @@ const (
 
 // Operator to implement managed collection for Google Prometheus Engine.
 type Operator struct {
-	logger  logr.Logger
-	opts    Options
-	client  client.Client
-	manager manager.Manager
+	logger          logr.Logger
+	opts            Options
+	client          client.Client
+	manager         manager.Manager
+	kubeClient      kubernetes.Interface
+	certHolder      *certHolder
+	registry        prometheus.Registerer
+	webhookListener net.Listener
 }
 
 // Options for the Operator.
@@ -131,12 +138,24 @@ type Options struct {
 	HostNetwork bool
 	// Priority class for the collector pods.
 	PriorityClass string
+	// TrustedCABundleConfigMap, if set, names a ConfigMap in OperatorNamespace
+	// holding an organization-wide trusted CA bundle (under the key
+	// trustedCABundleFilename). It is mounted into the rule-evaluator and
+	// used as the default tls_config.ca_file for https Alertmanager targets
+	// that don't specify their own CA.
+	TrustedCABundleConfigMap string
 	// Certificate of the server in base 64.
 	TLSCert string
 	// Key of the server in base 64.
 	TLSKey string
 	// Certificate authority in base 64.
 	CACert string
+	// TLSCertFile, if set, is a path to a PEM-encoded serving certificate that
+	// is reloaded whenever it changes on disk, e.g. one mounted from a
+	// cert-manager- or GKE-issued Secret. Mutually exclusive with TLSCert.
+	TLSCertFile string
+	// TLSKeyFile is the key file counterpart to TLSCertFile.
+	TLSKeyFile string
 	// Endpoint of the Cloud Monitoring API to be used by all collectors.
 	CloudMonitoringEndpoint string
 	// Webhook serving address.
@@ -159,6 +178,10 @@ type Options struct {
 	EvaluatorCPULimit int64
 	// How managed collection was provisioned.
 	Mode string
+	// LoggingConfig configures the format (text or json) and verbosity of
+	// the operator's logs. json is required for structured fields to survive
+	// ingestion into Cloud Logging. If nil, component-base's defaults apply.
+	LoggingConfig *logsapiv1.LoggingConfiguration
 }
 
 func (o *Options) defaultAndValidate(logger logr.Logger) error {
@@ -239,11 +262,29 @@ func (o *Options) defaultAndValidate(logger logr.Logger) error {
 	default:
 		return errors.New("--mode must be one of {'kubectl', 'gke', 'gke-auto', 'on-prem', 'baremetal}")
 	}
+	if (o.TLSCertFile == "") != (o.TLSKeyFile == "") {
+		return errors.New("TLSCertFile and TLSKeyFile must both be set")
+	}
+	if (o.TLSCert == "") != (o.TLSKey == "") {
+		return errors.New("TLSCert and TLSKey must both be set")
+	}
+	if o.TLSCertFile != "" && (o.TLSCert != "" || o.TLSKey != "") {
+		return errors.New("TLSCertFile/TLSKeyFile and TLSCert/TLSKey are mutually exclusive")
+	}
 	return nil
 }
 
 // New instantiates a new Operator.
 func New(logger logr.Logger, clientConfig *rest.Config, registry prometheus.Registerer, opts Options) (*Operator, error) {
+	// Apply the logging format (text/json) and initial verbosity before
+	// logger is used for anything else, so every subsequent log line,
+	// including ones emitted while standing up the manager below, is
+	// already in the configured format.
+	if opts.LoggingConfig != nil {
+		if err := logsapiv1.ValidateAndApply(opts.LoggingConfig, nil); err != nil {
+			return nil, errors.Wrap(err, "invalid logging configuration")
+		}
+	}
 	if err := opts.defaultAndValidate(logger); err != nil {
 		return nil, errors.Wrap(err, "invalid options")
 	}
@@ -261,7 +302,16 @@ func New(logger logr.Logger, clientConfig *rest.Config, registry prometheus.Regi
 	if err := monitoringv1.AddToScheme(sc); err != nil {
 		return nil, errors.Wrap(err, "add monitoringv1 scheme")
 	}
-	host, portStr, err := net.SplitHostPort(opts.ListenAddr)
+	// Bind the webhook listener ourselves, rather than letting the manager
+	// do it internally from Host/Port, so that ":0" (an ephemeral port) can
+	// be requested -- e.g. by tests that need to avoid colliding with other
+	// parallel test runs on the same host -- and the resolved port can be
+	// read back afterwards via Operator.WebhookAddr.
+	listener, err := net.Listen("tcp", opts.ListenAddr)
+	if err != nil {
+		return nil, errors.Wrap(err, "listen on webhook address")
+	}
+	host, portStr, err := net.SplitHostPort(listener.Addr().String())
 	if err != nil {
 		return nil, errors.Wrap(err, "invalid listen address")
 	}
@@ -281,20 +331,36 @@ func New(logger logr.Logger, clientConfig *rest.Config, registry prometheus.Regi
 	if err != nil {
 		return nil, errors.Wrap(err, "create controller manager")
 	}
+	mgr.GetWebhookServer().Listener = listener
 	client, err := client.New(clientConfig, client.Options{Scheme: sc})
 	if err != nil {
 		return nil, errors.Wrap(err, "create client")
 	}
+	kubeClient, err := kubernetes.NewForConfig(clientConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "create Kubernetes clientset")
+	}
 
 	op := &Operator{
-		logger:  logger,
-		opts:    opts,
-		client:  client,
-		manager: mgr,
+		logger:          logger,
+		opts:            opts,
+		client:          client,
+		manager:         mgr,
+		kubeClient:      kubeClient,
+		certHolder:      &certHolder{},
+		registry:        registry,
+		webhookListener: listener,
 	}
 	return op, nil
 }
 
+// WebhookAddr returns the address the webhook server is listening on. If
+// Options.ListenAddr requested the ephemeral port ":0", this reports the
+// port the OS actually assigned.
+func (o *Operator) WebhookAddr() net.Addr {
+	return o.webhookListener.Addr()
+}
+
 // setupAdmissionWebhooks configures validating webhooks for the operator-managed
 // custom resources and registers handlers with the webhook server.
 func (o *Operator) setupAdmissionWebhooks(ctx context.Context) error {
@@ -307,40 +373,65 @@ func (o *Operator) setupAdmissionWebhooks(ctx context.Context) error {
 		o.logger.Error(err, "msg", "Deleting legacy ValidatingWebhookConfiguration failed")
 	}
 
-	// Write provided cert files.
-	caBundle, err := o.ensureCerts(ctx, o.manager.GetWebhookServer().CertDir)
-	if err != nil {
-		return err
-	}
+	s := o.manager.GetWebhookServer()
 
-	// Keep setting the caBundle in the expected webhook configurations.
-	go func() {
+	// Install our certificate holder ahead of controller-runtime's default
+	// file-based lookup, so rotated material written by runCertManager takes
+	// effect without a restart.
+	s.TLSOpts = append(s.TLSOpts, o.certHolder.withGetCertificate)
+
+	patchWebhookConfigs := func(caBundle []byte) {
 		// Only inject if we've an explicit CA bundle ourselves. Otherwise the webhook configs
 		// may already have been created with one.
 		if len(caBundle) == 0 {
 			return
 		}
+		if err := o.setValidatingWebhookCABundle(ctx, caBundle); err != nil {
+			o.logger.Error(err, "Setting CA bundle for ValidatingWebhookConfiguration failed")
+		}
+		if err := o.setMutatingWebhookCABundle(ctx, caBundle); err != nil {
+			o.logger.Error(err, "Setting CA bundle for MutatingWebhookConfiguration failed")
+		}
+	}
+
+	// rotated hands each rotated CA bundle to the fallback loop below, so
+	// that loop keeps setting the current bundle rather than the initial one.
+	rotated := make(chan []byte, 1)
+	onRotate := func(caBundle []byte) {
+		patchWebhookConfigs(caBundle)
+		select {
+		case rotated <- caBundle:
+		case <-ctx.Done():
+		}
+	}
+
+	// Write the initial cert/key pair and start the goroutine that keeps it
+	// current, repatching the webhook configurations immediately on every
+	// rotation rather than waiting for the minute-long fallback poll below.
+	caBundle, err := o.runCertManager(ctx, s.CertDir, o.certHolder, onRotate)
+	if err != nil {
+		return err
+	}
+
+	// Keep setting the caBundle in the expected webhook configurations as a
+	// fallback, in case the webhook configs were recreated without one
+	// in between rotations.
+	go func() {
 		// Initial sleep for the client to initialize before our first calls.
 		// Ideally we could explicitly wait for it.
 		time.Sleep(5 * time.Second)
 
 		for {
-			if err := o.setValidatingWebhookCABundle(ctx, caBundle); err != nil {
-				o.logger.Error(err, "Setting CA bundle for ValidatingWebhookConfiguration failed")
-			}
-			if err := o.setMutatingWebhookCABundle(ctx, caBundle); err != nil {
-				o.logger.Error(err, "Setting CA bundle for MutatingWebhookConfiguration failed")
-			}
+			patchWebhookConfigs(caBundle)
 			select {
 			case <-ctx.Done():
 				return
+			case caBundle = <-rotated:
 			case <-time.After(time.Minute):
 			}
 		}
 	}()
 
-	s := o.manager.GetWebhookServer()
-
 	// Validating webhooks.
 	s.Register(
 		validatePath(monitoringv1.PodMonitoringResource()),
@@ -350,10 +441,21 @@ func (o *Operator) setupAdmissionWebhooks(ctx context.Context) error {
 		validatePath(monitoringv1.ClusterPodMonitoringResource()),
 		admission.ValidatingWebhookFor(&monitoringv1.ClusterPodMonitoring{}),
 	)
+	s.Register(
+		validatePath(monitoringv1.ServiceMonitoringResource()),
+		admission.WithCustomValidator(&monitoringv1.ServiceMonitoring{}, &serviceMonitoringValidator{}),
+	)
+	s.Register(
+		validatePath(monitoringv1.ClusterServiceMonitoringResource()),
+		admission.WithCustomValidator(&monitoringv1.ClusterServiceMonitoring{}, &clusterServiceMonitoringValidator{}),
+	)
 	s.Register(
 		validatePath(monitoringv1.OperatorConfigResource()),
 		admission.WithCustomValidator(&monitoringv1.OperatorConfig{}, &operatorConfigValidator{
 			namespace: o.opts.PublicNamespace,
+			logger:    o.logger,
+			client:    o.client,
+			sar:       o.kubeClient.AuthorizationV1().SubjectAccessReviews(),
 		}),
 	)
 	s.Register(
@@ -381,6 +483,14 @@ func (o *Operator) setupAdmissionWebhooks(ctx context.Context) error {
 		defaultPath(monitoringv1.ClusterPodMonitoringResource()),
 		admission.WithCustomDefaulter(&monitoringv1.ClusterPodMonitoring{}, &clusterPodMonitoringDefaulter{}),
 	)
+	s.Register(
+		defaultPath(monitoringv1.ServiceMonitoringResource()),
+		admission.WithCustomDefaulter(&monitoringv1.ServiceMonitoring{}, &serviceMonitoringDefaulter{}),
+	)
+	s.Register(
+		defaultPath(monitoringv1.ClusterServiceMonitoringResource()),
+		admission.WithCustomDefaulter(&monitoringv1.ClusterServiceMonitoring{}, &clusterServiceMonitoringDefaulter{}),
+	)
 	return nil
 }
 
@@ -402,58 +512,38 @@ func (o *Operator) Run(ctx context.Context) error {
 	if err := setupOperatorConfigControllers(o); err != nil {
 		return errors.Wrap(err, "setup rule-evaluator controllers")
 	}
+	if err := o.setupLifecycleController(); err != nil {
+		return errors.Wrap(err, "setup lifecycle controller")
+	}
 
 	o.logger.Info("starting GMP operator")
 
 	return o.manager.Start(ctx)
 }
 
-// ensureCerts writes the cert/key files to the specified directory.
-// If cert/key are not avalilable, generate them.
-func (o *Operator) ensureCerts(ctx context.Context, dir string) ([]byte, error) {
-	var (
-		crt, key, caData []byte
-		err              error
+
+// setupLifecycleController registers the PromQL-driven staleness controller
+// that garbage-collects PodMonitoring/ClusterPodMonitoring resources
+// declaring spec.lifecycle, as a manager.Runnable alongside the
+// reconciler-based controllers above.
+func (o *Operator) setupLifecycleController() error {
+	lc, err := lifecycle.NewController(
+		o.manager.GetClient(),
+		o.manager.GetEventRecorderFor("gmp-operator-lifecycle"),
+		o.logger,
+		o.registry,
+		lifecycle.Options{
+			PublicNamespace:    o.opts.PublicNamespace,
+			OperatorConfigName: NameOperatorConfig,
+			// Evaluate deletion rules against the in-cluster rule-evaluator,
+			// which already embeds a query backend (see cmd/rule-evaluator).
+			QueryURL: fmt.Sprintf("http://%s.%s.svc:%d", NameRuleEvaluator, o.opts.OperatorNamespace, RuleEvaluatorPort),
+		},
 	)
-	if o.opts.TLSKey != "" && o.opts.TLSCert != "" {
-		crt, err = base64.StdEncoding.DecodeString(o.opts.TLSCert)
-		if err != nil {
-			return nil, errors.Wrap(err, "decoding TLS certificate")
-		}
-		key, err = base64.StdEncoding.DecodeString(o.opts.TLSKey)
-		if err != nil {
-			return nil, errors.Wrap(err, "decoding TLS key")
-		}
-		if o.opts.CACert != "" {
-			caData, err = base64.StdEncoding.DecodeString(o.opts.CACert)
-			if err != nil {
-				return nil, errors.Wrap(err, "decoding certificate authority")
-			}
-		}
-	} else if o.opts.TLSKey == "" && o.opts.TLSCert == "" && o.opts.CACert == "" {
-		// Generate a self-signed pair if none was explicitly provided. It will be valid
-		// for 1 year.
-		// TODO(freinartz): re-generate at runtime and update the ValidatingWebhookConfiguration
-		// at runtime whenever the files change.
-		fqdn := fmt.Sprintf("%s.%s.svc", NameOperator, o.opts.OperatorNamespace)
-
-		crt, key, err = cert.GenerateSelfSignedCertKey(fqdn, nil, nil)
-		if err != nil {
-			return nil, errors.Wrap(err, "generate self-signed TLS key pair")
-		}
-		// Use crt as the ca in the the self-sign case.
-		caData = crt
-	} else {
-		return nil, errors.Errorf("Flags key-base64 and cert-base64 must both be set.")
-	}
-	// Create cert/key files.
-	if err := ioutil.WriteFile(filepath.Join(dir, "tls.crt"), crt, 0666); err != nil {
-		return nil, errors.Wrap(err, "create cert file")
-	}
-	if err := ioutil.WriteFile(filepath.Join(dir, "tls.key"), key, 0666); err != nil {
-		return nil, errors.Wrap(err, "create key file")
+	if err != nil {
+		return errors.Wrap(err, "create lifecycle controller")
 	}
-	return caData, nil
+	return o.manager.Add(lc)
 }
 
 // namespacedNamePredicate is an event filter predicate that only allows events with
@@ -0,0 +1,104 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"strconv"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	defaultServiceMonitoringPath     = "/metrics"
+	defaultServiceMonitoringInterval = "1m"
+)
+
+// defaultScrapeEndpoints fills in the Path and Interval defaults shared by
+// ServiceMonitoring and ClusterServiceMonitoring endpoints. Port is left
+// alone, since it has no sensible default and is required.
+func defaultScrapeEndpoints(eps []monitoringv1.ScrapeEndpoint) {
+	for i := range eps {
+		if eps[i].Path == "" {
+			eps[i].Path = defaultServiceMonitoringPath
+		}
+		if eps[i].Interval == "" {
+			eps[i].Interval = defaultServiceMonitoringInterval
+		}
+	}
+}
+
+// validateServiceEndpoints enforces that every endpoint names a Service
+// port rather than a port number, since role: endpoints discovery resolves
+// ports by name.
+func validateServiceEndpoints(eps []monitoringv1.ScrapeEndpoint) error {
+	for i, ep := range eps {
+		if ep.Port == "" {
+			return errors.Errorf("endpoints[%d]: port must be set", i)
+		}
+		if _, err := strconv.Atoi(ep.Port); err == nil {
+			return errors.Errorf("endpoints[%d]: port %q must name a Service port, not a port number", i, ep.Port)
+		}
+	}
+	return nil
+}
+
+type serviceMonitoringDefaulter struct{}
+
+func (d *serviceMonitoringDefaulter) Default(ctx context.Context, o runtime.Object) error {
+	sm := o.(*monitoringv1.ServiceMonitoring)
+	defaultScrapeEndpoints(sm.Spec.Endpoints)
+	return nil
+}
+
+type serviceMonitoringValidator struct{}
+
+func (v *serviceMonitoringValidator) ValidateCreate(ctx context.Context, o runtime.Object) error {
+	sm := o.(*monitoringv1.ServiceMonitoring)
+	return validateServiceEndpoints(sm.Spec.Endpoints)
+}
+
+func (v *serviceMonitoringValidator) ValidateUpdate(ctx context.Context, _, o runtime.Object) error {
+	return v.ValidateCreate(ctx, o)
+}
+
+func (v *serviceMonitoringValidator) ValidateDelete(ctx context.Context, o runtime.Object) error {
+	return nil
+}
+
+type clusterServiceMonitoringDefaulter struct{}
+
+func (d *clusterServiceMonitoringDefaulter) Default(ctx context.Context, o runtime.Object) error {
+	csm := o.(*monitoringv1.ClusterServiceMonitoring)
+	defaultScrapeEndpoints(csm.Spec.Endpoints)
+	return nil
+}
+
+type clusterServiceMonitoringValidator struct{}
+
+func (v *clusterServiceMonitoringValidator) ValidateCreate(ctx context.Context, o runtime.Object) error {
+	csm := o.(*monitoringv1.ClusterServiceMonitoring)
+	return validateServiceEndpoints(csm.Spec.Endpoints)
+}
+
+func (v *clusterServiceMonitoringValidator) ValidateUpdate(ctx context.Context, _, o runtime.Object) error {
+	return v.ValidateCreate(ctx, o)
+}
+
+func (v *clusterServiceMonitoringValidator) ValidateDelete(ctx context.Context, o runtime.Object) error {
+	return nil
+}
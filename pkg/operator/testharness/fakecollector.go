@@ -0,0 +1,53 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testharness
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+// FakeCollector is an in-process stand-in for a Prometheus collector's
+// /metrics endpoint, for tests that need something real for the operator
+// to discover and scrape rather than asserting against rendered config
+// alone.
+type FakeCollector struct {
+	srv *httptest.Server
+	// Metrics is served verbatim as the body of every /metrics request.
+	// Tests can mutate it between scrapes to simulate changing series.
+	Metrics string
+}
+
+// NewFakeCollector starts a FakeCollector serving metrics on an
+// OS-assigned loopback port. Call Close when done with it.
+func NewFakeCollector(metrics string) *FakeCollector {
+	c := &FakeCollector{Metrics: metrics}
+	c.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, c.Metrics)
+	}))
+	return c
+}
+
+// URL is the base URL of the fake collector, e.g. for use as a
+// PodMonitoring/ServiceMonitoring endpoint target in tests.
+func (c *FakeCollector) URL() string {
+	return c.srv.URL
+}
+
+// Close shuts down the fake collector's HTTP server.
+func (c *FakeCollector) Close() {
+	c.srv.Close()
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testharness
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/yaml"
+)
+
+// updateGolden regenerates golden files in place instead of comparing
+// against them, following the same convention as golden-file tests
+// elsewhere in the Go ecosystem.
+var updateGolden = flag.Bool("update-golden", false, "write -- rather than compare against -- golden files")
+
+// AssertGoldenYAML marshals got to YAML and compares it against the
+// contents of the golden file at path, failing t if they differ. Pass
+// -update-golden to regenerate path from got instead.
+//
+// This is deliberately not specific to any one rendered object (e.g. a
+// collector DaemonSet pod spec): this snapshot has no collector DaemonSet
+// pod-spec builder to call, so tests exercising it don't exist yet. Any
+// caller with a Kubernetes object can use this against its golden file in
+// the meantime.
+func AssertGoldenYAML(t testing.TB, path string, got interface{}) {
+	t.Helper()
+
+	gotYAML, err := yaml.Marshal(got)
+	if err != nil {
+		t.Fatalf("marshal %T to YAML: %s", got, err)
+	}
+
+	if *updateGolden {
+		if err := os.WriteFile(path, gotYAML, 0o644); err != nil {
+			t.Fatalf("write golden file %q: %s", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file %q (run with -update-golden to create it): %s", path, err)
+	}
+	if diff := cmp.Diff(string(want), string(gotYAML)); diff != "" {
+		t.Errorf("%q differs from golden file (-want +got):\n%s", path, diff)
+	}
+}